@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"dmarc-viewer/internal/config"
+	"dmarc-viewer/internal/doctor"
+	"dmarc-viewer/internal/imap"
+	"dmarc-viewer/internal/logging"
+)
+
+// runCheckConfig validates a config file without starting anything
+func runCheckConfig(args []string) int {
+	fs := pflag.NewFlagSet("check-config", pflag.ContinueOnError)
+	configFile := fs.String("config", "", "Path to config file (default: search the standard locations)")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return exitOK
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitConfigError
+	}
+
+	if *configFile == "" {
+		*configFile = config.FindConfigFile()
+		if *configFile == "" {
+			fmt.Fprintf(os.Stderr, "No config file found in %s\n", strings.Join(config.ConfigSearchDirs(), ", "))
+			return exitConfigError
+		}
+	}
+
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		printConfigErrors(*configFile, err)
+		return exitConfigError
+	}
+
+	// Loading only checks the settings themselves; also make sure the files
+	// the configuration writes to can be created and the CA bundle can be read
+	if err := errors.Join(config.CheckDatabasePath(cfg.Database.Path), config.CheckOutputPaths(cfg), config.CheckCAFile(cfg)); err != nil {
+		printConfigErrors(*configFile, fmt.Errorf("config validation failed: %w", err))
+		return exitConfigError
+	}
+
+	fmt.Printf("%s: configuration is valid\n", *configFile)
+	return exitOK
+}
+
+// printConfigErrors reports a configuration error on stderr. Validation
+// reports every problem at once, so those are listed one per line.
+func printConfigErrors(source string, err error) {
+	var problems interface{ Unwrap() []error }
+	if errors.As(err, &problems) {
+		fmt.Fprintf(os.Stderr, "%s: config validation failed:\n", source)
+		for _, problem := range problems.Unwrap() {
+			fmt.Fprintf(os.Stderr, "  - %v\n", problem)
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s: %v\n", source, err)
+}
+
+// runConfig handles the config subcommands
+func runConfig(args []string) int {
+	if len(args) != 1 || args[0] != "schema" {
+		fmt.Fprintln(os.Stderr, "Usage: dmarc-viewer config schema")
+		return exitConfigError
+	}
+
+	schema, err := config.Schema()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating schema: %v\n", err)
+		return exitConfigError
+	}
+
+	fmt.Println(string(schema))
+	return exitOK
+}
+
+// runDoctor runs the setup self-test and prints a pass/fail checklist. It
+// accepts the same flags as the main command, plus --result-file.
+func runDoctor() int {
+	resultFile := pflag.String("result-file", "", "Write a JSON summary of the checks to this file")
+
+	cfg, err := config.LoadWithFlags()
+	if errors.Is(err, pflag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+		return finishDoctor(*resultFile, exitConfigError, nil)
+	}
+
+	// With --log-level debug the IMAP check logs its (redacted) protocol
+	// exchange. A log or trace file that can't be opened doesn't stop the
+	// self-test; the checklist reports it.
+	logger, logCloser, err := logging.New(cfg.Logging)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: logging to stderr: %v\n", err)
+		logger = logging.Stderr(cfg.Logging)
+	} else {
+		defer logCloser.Close()
+	}
+	slog.SetDefault(logger)
+
+	if cfg.IMAP.Trace.Enabled {
+		tracer, err := imap.NewTracer(cfg.IMAP.Trace)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: IMAP trace disabled: %v\n", err)
+		} else {
+			defer tracer.Close()
+			imap.SetTracer(tracer)
+		}
+	}
+
+	fmt.Println("=== DMARC Report Viewer Self-Test ===")
+	fmt.Println()
+
+	results := doctor.Run(context.Background(), doctor.Checks(cfg))
+	steps := make([]stepResult, 0, len(results))
+	passed := 0
+	for _, r := range results {
+		step := stepResult{Name: r.Name, Passed: r.Err == nil, Detail: r.Detail}
+		if r.Err != nil {
+			step.Detail = ""
+			step.Error = r.Err.Error()
+			fmt.Printf("[FAIL] %s\n", r.Name)
+			for _, line := range strings.Split(r.Err.Error(), "\n") {
+				fmt.Printf("       %s\n", line)
+			}
+		} else {
+			passed++
+			if r.Detail != "" {
+				fmt.Printf("[PASS] %s (%s)\n", r.Name, r.Detail)
+			} else {
+				fmt.Printf("[PASS] %s\n", r.Name)
+			}
+		}
+		steps = append(steps, step)
+	}
+
+	fmt.Println()
+	fmt.Printf("%d of %d checks passed\n", passed, len(results))
+
+	return finishDoctor(*resultFile, doctorExitCode(results), steps)
+}
+
+// doctorExitCode picks the exit code for a set of check results: an invalid
+// configuration outranks unreachable services, which outrank other failures
+func doctorExitCode(results []doctor.Result) int {
+	code := exitOK
+	for _, r := range results {
+		switch {
+		case r.Err == nil:
+		case r.Kind == doctor.Configuration:
+			return exitConfigError
+		case r.Kind == doctor.Remote:
+			code = exitConnectionFailure
+		case code == exitOK:
+			code = exitPartialFailure
+		}
+	}
+	return code
+}
+
+// finishDoctor writes the optional result file and returns the exit code
+func finishDoctor(resultFile string, code int, steps []stepResult) int {
+	if resultFile != "" {
+		if err := writeResultFile(resultFile, "doctor", code, steps); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing result file: %v\n", err)
+		}
+	}
+	return code
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Exit codes shared by all commands, so cron wrappers and systemd OnFailure
+// handlers can tell failure classes apart
+const (
+	exitOK                = 0 // everything succeeded
+	exitConfigError       = 1 // configuration missing, unreadable, or invalid
+	exitConnectionFailure = 2 // a remote service (DNS, IMAP) could not be reached or refused login
+	exitPartialFailure    = 3 // the command ran but some steps failed
+)
+
+// exitStatus names each exit code in machine-readable results
+var exitStatus = map[int]string{
+	exitOK:                "ok",
+	exitConfigError:       "config_error",
+	exitConnectionFailure: "connection_failure",
+	exitPartialFailure:    "partial_failure",
+}
+
+// stepResult is the outcome of one step of a command in a result file
+type stepResult struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// commandResult is the machine-readable summary written by --result-file
+type commandResult struct {
+	Command    string       `json:"command"`
+	Status     string       `json:"status"`
+	ExitCode   int          `json:"exit_code"`
+	FinishedAt time.Time    `json:"finished_at"`
+	Steps      []stepResult `json:"steps,omitempty"`
+}
+
+// writeResultFile writes the result as JSON to path, replacing any previous
+// file atomically so readers never see a partial result
+func writeResultFile(path, command string, code int, steps []stepResult) error {
+	data, err := json.MarshalIndent(commandResult{
+		Command:    command,
+		Status:     exitStatus[code],
+		ExitCode:   code,
+		FinishedAt: time.Now().UTC(),
+		Steps:      steps,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".result-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dmarc-viewer/internal/doctor"
+)
+
+func TestDoctorExitCode(t *testing.T) {
+	failed := errors.New("failed")
+
+	tests := []struct {
+		name     string
+		results  []doctor.Result
+		expected int
+	}{
+		{"no checks", nil, exitOK},
+		{"all passed", []doctor.Result{
+			{Kind: doctor.Configuration},
+			{Kind: doctor.Local},
+			{Kind: doctor.Remote},
+		}, exitOK},
+		{"local failure", []doctor.Result{
+			{Kind: doctor.Local, Err: failed},
+			{Kind: doctor.Remote},
+		}, exitPartialFailure},
+		{"remote failure", []doctor.Result{
+			{Kind: doctor.Local},
+			{Kind: doctor.Remote, Err: failed},
+		}, exitConnectionFailure},
+		{"remote outranks earlier local", []doctor.Result{
+			{Kind: doctor.Local, Err: failed},
+			{Kind: doctor.Remote, Err: failed},
+		}, exitConnectionFailure},
+		{"remote outranks later local", []doctor.Result{
+			{Kind: doctor.Remote, Err: failed},
+			{Kind: doctor.Local, Err: failed},
+		}, exitConnectionFailure},
+		{"configuration outranks remote", []doctor.Result{
+			{Kind: doctor.Remote, Err: failed},
+			{Kind: doctor.Configuration, Err: failed},
+		}, exitConfigError},
+	}
+
+	for _, tt := range tests {
+		if actual := doctorExitCode(tt.results); actual != tt.expected {
+			t.Errorf("%s: expected exit code %d, got %d", tt.name, tt.expected, actual)
+		}
+	}
+}
+
+func TestWriteResultFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "result.json")
+	steps := []stepResult{{Name: "check", Passed: false, Error: "broken"}}
+
+	if err := os.WriteFile(path, []byte("previous"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeResultFile(path, "doctor", exitPartialFailure, steps); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result commandResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("Expected valid JSON, got: %v", err)
+	}
+	if result.Command != "doctor" || result.Status != "partial_failure" || result.ExitCode != exitPartialFailure {
+		t.Errorf("Expected doctor partial_failure result, got %+v", result)
+	}
+	if len(result.Steps) != 1 || result.Steps[0].Error != "broken" {
+		t.Errorf("Expected the failed step, got %+v", result.Steps)
+	}
+
+	assertOnlyEntries(t, dir, "result.json")
+}
+
+func TestWriteResultFile_FailureLeavesNoPartialFile(t *testing.T) {
+	dir := t.TempDir()
+
+	// A non-empty directory at the result path makes the final rename fail
+	path := filepath.Join(dir, "result.json")
+	if err := os.MkdirAll(filepath.Join(path, "blocker"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeResultFile(path, "doctor", exitOK, nil); err == nil {
+		t.Fatal("Expected an error when the result path can't be replaced")
+	}
+
+	assertOnlyEntries(t, dir, "result.json")
+}
+
+// assertOnlyEntries fails unless dir contains exactly the named entries
+func assertOnlyEntries(t *testing.T, dir string, names ...string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual []string
+	for _, e := range entries {
+		actual = append(actual, e.Name())
+	}
+	if len(actual) != len(names) {
+		t.Fatalf("Expected entries %v, got %v", names, actual)
+	}
+	for i, name := range names {
+		if actual[i] != name {
+			t.Errorf("Expected entries %v, got %v", names, actual)
+		}
+	}
+}
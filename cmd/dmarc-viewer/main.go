@@ -1,31 +1,81 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
 	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/pflag"
 
 	"dmarc-viewer/internal/config"
+	"dmarc-viewer/internal/logging"
 )
 
 func main() {
+	// Dispatch subcommands before the default flag handling
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "check-config":
+			os.Exit(runCheckConfig(os.Args[2:]))
+		case "config":
+			os.Exit(runConfig(os.Args[2:]))
+		case "doctor":
+			os.Exit(runDoctor())
+		}
+	}
+
 	// Load configuration with CLI flags
 	cfg, err := config.LoadWithFlags()
+	if errors.Is(err, pflag.ErrHelp) {
+		os.Exit(exitOK)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitConfigError)
+	}
+	if err := config.Validate(cfg); err != nil {
+		printConfigErrors(displayOrDefault(cfg.File, "configuration"), fmt.Errorf("config validation failed: %w", err))
+		os.Exit(exitConfigError)
 	}
 
+	// Set up logging
+	logger, logCloser, err := logging.New(cfg.Logging)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error setting up logging: %v\n", err)
+		os.Exit(exitConfigError)
+	}
+	defer logCloser.Close()
+	slog.SetDefault(logger)
+	slog.Info("configuration loaded", "file", cfg.File)
+
 	// Print loaded configuration
 	fmt.Println("=== DMARC Report Viewer Configuration ===")
 	fmt.Println()
 
+	fmt.Printf("Config File: %s\n", displayOrDefault(cfg.File, "(none found, using defaults and environment)"))
+	fmt.Println()
+
 	fmt.Println("IMAP Configuration:")
+	fmt.Printf("  Provider: %s\n", displayOrDefault(cfg.IMAP.Provider, "(custom)"))
 	fmt.Printf("  Host:     %s\n", cfg.IMAP.Host)
 	fmt.Printf("  Port:     %d\n", cfg.IMAP.Port)
 	fmt.Printf("  Username: %s\n", cfg.IMAP.Username)
 	fmt.Printf("  Password: %s\n", maskPassword(cfg.IMAP.Password))
 	fmt.Printf("  Folder:   %s\n", cfg.IMAP.Folder)
 	fmt.Printf("  Use TLS:  %t\n", cfg.IMAP.UseTLS)
+	fmt.Printf("  TLS CA:   %s\n", displayOrDefault(cfg.IMAP.TLS.CAFile, "(system roots)"))
+	fmt.Printf("  TLS Skip: %t\n", cfg.IMAP.TLS.InsecureSkipVerify)
+	fmt.Printf("  TLS Pins: %d\n", len(cfg.IMAP.TLS.Fingerprints))
+	fmt.Printf("  Breaker:  %s\n", formatCircuitBreaker(cfg.IMAP.CircuitBreaker))
+	if cfg.IMAP.Trace.Enabled {
+		fmt.Printf("  Trace:    %s\n", cfg.IMAP.Trace.File)
+	} else {
+		fmt.Printf("  Trace:    disabled\n")
+	}
 	fmt.Println()
 
 	fmt.Println("Database Configuration:")
@@ -45,6 +95,27 @@ func main() {
 	fmt.Println("Logging Configuration:")
 	fmt.Printf("  Level:  %s\n", cfg.Logging.Level)
 	fmt.Printf("  Format: %s\n", cfg.Logging.Format)
+	fmt.Printf("  Output: %s\n", formatLogOutput(cfg.Logging))
+	if len(cfg.Logging.Levels) > 0 {
+		fmt.Printf("  Levels: %s\n", formatLogLevels(cfg.Logging.Levels))
+	}
+	fmt.Println()
+
+	fmt.Println("Privacy Configuration:")
+	fmt.Printf("  Source IP:          %s\n", cfg.Privacy.SourceIP)
+	fmt.Printf("  Envelope Addresses: %s\n", cfg.Privacy.EnvelopeAddresses)
+	fmt.Printf("  Recipients:         %s\n", cfg.Privacy.Recipients)
+	fmt.Println()
+
+	fmt.Println("DNS Configuration:")
+	fmt.Printf("  Server:      %s\n", displayOrDefault(cfg.DNS.Server, "(system resolver)"))
+	fmt.Printf("  Protocol:    %s\n", cfg.DNS.Protocol)
+	fmt.Printf("  Timeout:     %s\n", cfg.DNS.Timeout)
+	fmt.Printf("  Concurrency: %d\n", cfg.DNS.Concurrency)
+	fmt.Println()
+
+	fmt.Println("Retry Configuration:")
+	fmt.Printf("  DNS: %s\n", formatRetryPolicy(cfg.Retry.DNS))
 	fmt.Println()
 
 	fmt.Println("Configuration loaded successfully!")
@@ -53,6 +124,48 @@ func main() {
 	fmt.Println("Full application functionality will be available in future tasks.")
 }
 
+// formatLogLevels lists per-component level overrides in component order
+func formatLogLevels(levels map[string]string) string {
+	var parts []string
+	for _, component := range slices.Sorted(maps.Keys(levels)) {
+		parts = append(parts, component+"="+levels[component])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatLogOutput describes where logs are written
+func formatLogOutput(cfg config.LogConfig) string {
+	if cfg.Output != "file" {
+		return displayOrDefault(cfg.Output, "stdout")
+	}
+	rotation := "no rotation"
+	switch {
+	case cfg.File.MaxSizeMB > 0 && cfg.File.RotateEvery != "":
+		rotation = fmt.Sprintf("rotate at %d MB or every %s", cfg.File.MaxSizeMB, cfg.File.RotateEvery)
+	case cfg.File.MaxSizeMB > 0:
+		rotation = fmt.Sprintf("rotate at %d MB", cfg.File.MaxSizeMB)
+	case cfg.File.RotateEvery != "":
+		rotation = fmt.Sprintf("rotate every %s", cfg.File.RotateEvery)
+	}
+	return fmt.Sprintf("%s (%s)", cfg.File.Path, rotation)
+}
+
+// formatCircuitBreaker summarizes circuit breaker settings on one line
+func formatCircuitBreaker(cb config.CircuitBreakerConfig) string {
+	if cb.FailureThreshold <= 0 {
+		return "disabled"
+	}
+	return fmt.Sprintf("open after %d failures for %s", cb.FailureThreshold, cb.Cooldown)
+}
+
+// formatRetryPolicy summarizes a retry policy on one line
+func formatRetryPolicy(p config.RetryPolicyConfig) string {
+	if p.MaxAttempts <= 1 {
+		return "no retries"
+	}
+	return fmt.Sprintf("%d attempts, %s to %s, x%g, jitter %g", p.MaxAttempts, p.InitialInterval, p.MaxInterval, p.Multiplier, p.Jitter)
+}
+
 // maskPassword masks the password for display, showing only first and last characters
 func maskPassword(password string) string {
 	if len(password) == 0 {
@@ -63,3 +176,11 @@ func maskPassword(password string) string {
 	}
 	return string(password[0]) + "***" + string(password[len(password)-1])
 }
+
+// displayOrDefault returns value, or fallback when value is empty
+func displayOrDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
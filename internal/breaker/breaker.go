@@ -0,0 +1,165 @@
+package breaker
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"dmarc-viewer/internal/config"
+)
+
+// State is the state of a circuit breaker
+type State int
+
+const (
+	// Closed lets calls through and counts consecutive failures
+	Closed State = iota
+	// Open rejects calls until the cooldown has passed
+	Open
+	// HalfOpen lets a single trial call through after the cooldown
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// ErrOpen is returned by Allow while the circuit is open
+var ErrOpen = errors.New("circuit breaker is open")
+
+// Breaker stops calls to a failing service after Threshold consecutive
+// failures, for Cooldown, instead of reconnecting in a tight loop. After the
+// cooldown one trial call is allowed: success closes the circuit again, a
+// failure reopens it for another cooldown. If the trial reports neither
+// within a further cooldown, another trial is allowed.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	// OnStateChange, if set, is called on every transition, e.g. to log or
+	// raise an alert when the circuit opens. It is called without the lock held.
+	OnStateChange func(from, to State)
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	trialAt  time.Time
+	now      func() time.Time
+}
+
+// New creates a closed Breaker. A threshold below 1 disables the breaker.
+func New(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// FromConfig creates a Breaker from circuit breaker configuration
+func FromConfig(cfg config.CircuitBreakerConfig) (*Breaker, error) {
+	var cooldown time.Duration
+	if cfg.Cooldown != "" {
+		d, err := time.ParseDuration(cfg.Cooldown)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cooldown: %w", err)
+		}
+		cooldown = d
+	}
+	return New(cfg.FailureThreshold, cooldown), nil
+}
+
+// State returns the current state, moving from Open to HalfOpen if the
+// cooldown has passed
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == Open && b.now().Sub(b.openedAt) >= b.cooldown {
+		return HalfOpen
+	}
+	return b.state
+}
+
+// Allow reports whether a call may be made now. It returns ErrOpen, wrapped
+// with the time remaining, while the circuit is open.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	if b.threshold < 1 || b.state == Closed {
+		b.mu.Unlock()
+		return nil
+	}
+
+	if b.state == Open {
+		remaining := b.cooldown - b.now().Sub(b.openedAt)
+		if remaining > 0 {
+			b.mu.Unlock()
+			return fmt.Errorf("%w, retrying in %s", ErrOpen, remaining.Round(time.Second))
+		}
+		b.trialAt = b.now()
+		b.transition(HalfOpen)
+		return nil
+	}
+
+	// Half-open: a trial call is in progress. One that never reported back,
+	// e.g., after a panic or an early return, is replaced once it has had a
+	// full cooldown.
+	if b.now().Sub(b.trialAt) >= b.cooldown {
+		b.trialAt = b.now()
+		b.mu.Unlock()
+		return nil
+	}
+	b.mu.Unlock()
+	return ErrOpen
+}
+
+// Success records a successful call, closing the circuit
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	b.failures = 0
+	if b.state == Closed {
+		b.mu.Unlock()
+		return
+	}
+	b.transition(Closed)
+}
+
+// Failure records a failed call, opening the circuit once the threshold of
+// consecutive failures is reached or if the half-open trial failed
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	if b.threshold < 1 {
+		b.mu.Unlock()
+		return
+	}
+
+	b.failures++
+	if b.state == HalfOpen || (b.state == Closed && b.failures >= b.threshold) {
+		b.openedAt = b.now()
+		b.transition(Open)
+		return
+	}
+	b.mu.Unlock()
+}
+
+// transition changes state and notifies OnStateChange. It must be called
+// with the lock held and releases it.
+func (b *Breaker) transition(to State) {
+	from := b.state
+	b.state = to
+	notify := b.OnStateChange
+	b.mu.Unlock()
+
+	if notify != nil && from != to {
+		notify(from, to)
+	}
+}
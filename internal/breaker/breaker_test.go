@@ -0,0 +1,161 @@
+package breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"dmarc-viewer/internal/config"
+)
+
+// newTestBreaker returns a Breaker driven by a fake clock
+func newTestBreaker(threshold int, cooldown time.Duration) (*Breaker, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := New(threshold, cooldown)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestBreaker_OpensAfterThreshold(t *testing.T) {
+	b, _ := newTestBreaker(3, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		b.Failure()
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Expected calls allowed after %d failures, got: %v", i+1, err)
+		}
+	}
+
+	b.Failure()
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Errorf("Expected ErrOpen after threshold, got: %v", err)
+	}
+	if b.State() != Open {
+		t.Errorf("Expected state open, got %s", b.State())
+	}
+}
+
+func TestBreaker_SuccessResetsFailures(t *testing.T) {
+	b, _ := newTestBreaker(2, time.Minute)
+
+	b.Failure()
+	b.Success()
+	b.Failure()
+	if err := b.Allow(); err != nil {
+		t.Errorf("Expected failures to be consecutive only, got: %v", err)
+	}
+}
+
+func TestBreaker_HalfOpenAfterCooldown(t *testing.T) {
+	b, now := newTestBreaker(1, time.Minute)
+	b.Failure()
+
+	*now = now.Add(time.Minute)
+	if b.State() != HalfOpen {
+		t.Errorf("Expected state half-open after cooldown, got %s", b.State())
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected trial call allowed, got: %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Errorf("Expected only one trial call, got: %v", err)
+	}
+
+	b.Success()
+	if b.State() != Closed {
+		t.Errorf("Expected state closed after successful trial, got %s", b.State())
+	}
+}
+
+func TestBreaker_StalledTrialReplaced(t *testing.T) {
+	b, now := newTestBreaker(1, time.Minute)
+	b.Failure()
+
+	*now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected trial call allowed, got: %v", err)
+	}
+
+	// The trial never reports Success or Failure
+	*now = now.Add(30 * time.Second)
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Errorf("Expected ErrOpen while the trial is in progress, got: %v", err)
+	}
+	*now = now.Add(30 * time.Second)
+	if err := b.Allow(); err != nil {
+		t.Errorf("Expected a new trial after a further cooldown, got: %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Errorf("Expected only one replacement trial, got: %v", err)
+	}
+
+	b.Success()
+	if b.State() != Closed {
+		t.Errorf("Expected state closed after the replacement trial succeeded, got %s", b.State())
+	}
+}
+
+func TestBreaker_TrialFailureReopens(t *testing.T) {
+	b, now := newTestBreaker(3, time.Minute)
+	for i := 0; i < 3; i++ {
+		b.Failure()
+	}
+
+	*now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected trial call allowed, got: %v", err)
+	}
+	b.Failure()
+
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Errorf("Expected circuit reopened after failed trial, got: %v", err)
+	}
+}
+
+func TestBreaker_OnStateChange(t *testing.T) {
+	b, now := newTestBreaker(1, time.Minute)
+
+	var transitions []string
+	b.OnStateChange = func(from, to State) {
+		transitions = append(transitions, from.String()+"->"+to.String())
+	}
+
+	b.Failure()
+	*now = now.Add(time.Minute)
+	_ = b.Allow()
+	b.Success()
+
+	expected := []string{"closed->open", "open->half-open", "half-open->closed"}
+	if len(transitions) != len(expected) {
+		t.Fatalf("Expected transitions %v, got %v", expected, transitions)
+	}
+	for i := range expected {
+		if transitions[i] != expected[i] {
+			t.Errorf("Expected transition %d to be %s, got %s", i, expected[i], transitions[i])
+		}
+	}
+}
+
+func TestBreaker_Disabled(t *testing.T) {
+	b, _ := newTestBreaker(0, time.Minute)
+	for i := 0; i < 10; i++ {
+		b.Failure()
+	}
+	if err := b.Allow(); err != nil {
+		t.Errorf("Expected disabled breaker to allow calls, got: %v", err)
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	b, err := FromConfig(config.CircuitBreakerConfig{FailureThreshold: 5, Cooldown: "10m"})
+	if err != nil {
+		t.Fatalf("FromConfig failed: %v", err)
+	}
+	if b.threshold != 5 || b.cooldown != 10*time.Minute {
+		t.Errorf("Expected threshold 5 and cooldown 10m, got %d and %s", b.threshold, b.cooldown)
+	}
+
+	if _, err := FromConfig(config.CircuitBreakerConfig{Cooldown: "later"}); err == nil {
+		t.Error("Expected error for invalid cooldown, got nil")
+	}
+}
@@ -1,9 +1,20 @@
 package config
 
 import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
@@ -15,16 +26,47 @@ type Config struct {
 	Web      WebConfig      `yaml:"web"`
 	Sync     SyncConfig     `yaml:"sync"`
 	Logging  LogConfig      `yaml:"logging"`
+	Privacy  PrivacyConfig  `yaml:"privacy"`
+	DNS      DNSConfig      `yaml:"dns"`
+	Retry    RetryConfig    `yaml:"retry"`
+
+	// File is the config file the settings were read from, empty if none
+	File string `yaml:"-"`
 }
 
 // IMAPConfig contains IMAP server connection settings
 type IMAPConfig struct {
-	Host     string `yaml:"host"`
-	Port     int    `yaml:"port"`
-	Username string `yaml:"username"`
-	Password string `yaml:"password"`
-	Folder   string `yaml:"folder"`
-	UseTLS   bool   `yaml:"use_tls"`
+	Provider string        `yaml:"provider"` // gmail, o365, fastmail, yahoo
+	Host     string        `yaml:"host"`
+	Port     int           `yaml:"port"`
+	Username string        `yaml:"username"`
+	Password string        `yaml:"password"`
+	Folder   string        `yaml:"folder"`
+	UseTLS   bool          `yaml:"use_tls"`
+	TLS      IMAPTLSConfig `yaml:"tls"`
+
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuit_breaker"`
+	Trace          IMAPTraceConfig      `yaml:"trace"`
+}
+
+// IMAPTraceConfig controls the wire-level IMAP trace used for troubleshooting
+type IMAPTraceConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	File    string `yaml:"file"` // credentials are removed before lines are written
+}
+
+// CircuitBreakerConfig stops reconnecting to a failing server for a cooldown
+// period after repeated failures
+type CircuitBreakerConfig struct {
+	FailureThreshold int    `yaml:"failure_threshold"` // consecutive failures before opening, 0 disables
+	Cooldown         string `yaml:"cooldown"`          // how long the circuit stays open, e.g., "10m"
+}
+
+// IMAPTLSConfig contains TLS verification settings for the IMAP connection
+type IMAPTLSConfig struct {
+	CAFile             string   `yaml:"ca_file"`              // PEM bundle trusted instead of system roots
+	InsecureSkipVerify bool     `yaml:"insecure_skip_verify"` // lab use only
+	Fingerprints       []string `yaml:"fingerprints"`         // SHA-256 of the server certificate, hex
 }
 
 // DatabaseConfig contains database settings
@@ -46,12 +88,94 @@ type SyncConfig struct {
 
 // LogConfig contains logging settings
 type LogConfig struct {
-	Level  string `yaml:"level"`  // debug, info, warn, error
-	Format string `yaml:"format"` // json, text
+	Level  string        `yaml:"level"`  // debug, info, warn, error
+	Format string        `yaml:"format"` // json, text
+	Output string        `yaml:"output"` // stdout, file, syslog
+	File   LogFileConfig `yaml:"file"`
+
+	// Levels overrides Level per component, e.g., {imap: debug, web: warn}
+	Levels map[string]string `yaml:"levels"`
+}
+
+// LogFileConfig contains settings for the file log output and its rotation
+type LogFileConfig struct {
+	Path        string `yaml:"path"`
+	MaxSizeMB   int    `yaml:"max_size_mb"`  // rotate when the file reaches this size, 0 disables
+	RotateEvery string `yaml:"rotate_every"` // rotate after this long, e.g., "24h"; empty disables
+	MaxBackups  int    `yaml:"max_backups"`  // rotated files kept, 0 keeps all
+	MaxAge      string `yaml:"max_age"`      // rotated files older than this are deleted, e.g., "720h"
+	Compress    bool   `yaml:"compress"`     // gzip rotated files
 }
 
-// Load reads configuration from YAML file, environment variables, and CLI flags
-// Priority order: CLI flags > Environment variables > YAML file
+// PrivacyConfig contains data-minimization settings applied before storage or export
+type PrivacyConfig struct {
+	SourceIP          string `yaml:"source_ip"`          // keep, truncate, hash
+	EnvelopeAddresses string `yaml:"envelope_addresses"` // envelope_from: keep, domain, drop
+	Recipients        string `yaml:"recipients"`         // envelope_to and forensic recipients: keep, hash, domain, drop; unset follows envelope_addresses
+	HashKey           string `yaml:"hash_key"`           // HMAC key used by the hash modes
+	IPv4Prefix        int    `yaml:"ipv4_prefix"`        // prefix length kept when truncating IPv4
+	IPv6Prefix        int    `yaml:"ipv6_prefix"`        // prefix length kept when truncating IPv6
+}
+
+// DNSConfig contains resolver settings for DNS enrichment lookups
+type DNSConfig struct {
+	Server      string `yaml:"server"`      // host or host:port; empty uses the system resolver
+	Protocol    string `yaml:"protocol"`    // udp, tcp, tls (DNS over TLS)
+	Timeout     string `yaml:"timeout"`     // per-lookup timeout, e.g., "5s"
+	Concurrency int    `yaml:"concurrency"` // maximum lookups in flight
+}
+
+// RetryConfig contains retry policies for subsystems that talk to remote
+// services. Policies are added alongside the clients that use them.
+type RetryConfig struct {
+	DNS RetryPolicyConfig `yaml:"dns"` // temporary DNS lookup failures
+}
+
+// RetryPolicyConfig describes exponential backoff for one subsystem
+type RetryPolicyConfig struct {
+	MaxAttempts     int     `yaml:"max_attempts"`     // total tries, including the first
+	InitialInterval string  `yaml:"initial_interval"` // delay before the first retry, e.g., "1s"
+	MaxInterval     string  `yaml:"max_interval"`     // upper bound on the delay
+	Multiplier      float64 `yaml:"multiplier"`       // delay growth per retry
+	Jitter          float64 `yaml:"jitter"`           // fraction each delay is randomized by, 0-1
+}
+
+// configExtensions lists the supported config file formats, in the order
+// they are tried when searching a directory
+var configExtensions = []string{"yaml", "yml", "toml", "json"}
+
+// ConfigSearchDirs returns the directories searched for a config file when
+// none is given explicitly, in priority order
+func ConfigSearchDirs() []string {
+	dirs := []string{"."}
+	if dir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(dir, "dmarc-viewer"))
+	}
+	return append(dirs, "/etc/dmarc-viewer")
+}
+
+// FindConfigFile returns the first config.{yaml,yml,toml,json} found in the
+// standard search directories, or an empty string if there is none
+func FindConfigFile() string {
+	return findConfigFile(ConfigSearchDirs())
+}
+
+// findConfigFile returns the first config file found in dirs
+func findConfigFile(dirs []string) string {
+	for _, dir := range dirs {
+		for _, ext := range configExtensions {
+			path := filepath.Join(dir, "config."+ext)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// Load reads configuration from a config file (YAML, TOML, or JSON, chosen by
+// extension) and environment variables
+// Priority order: Environment variables > config file
 func Load(configFile string) (*Config, error) {
 	v := viper.New()
 
@@ -67,34 +191,40 @@ func Load(configFile string) (*Config, error) {
 	}
 
 	// Read from environment variables with DMARC_ prefix
-	v.SetEnvPrefix("DMARC")
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	v.AutomaticEnv()
+	readEnv(v)
+
+	// Fill in connection settings for a known provider
+	applyProviderPreset(v)
 
 	// Unmarshal into Config struct
-	var cfg Config
-	if err := v.Unmarshal(&cfg); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	cfg, err := unmarshal(v)
+	if err != nil {
+		return nil, err
 	}
 
+	cfg.File = configFile
+
 	// Validate required fields
-	if err := validate(&cfg); err != nil {
+	if err := validate(cfg); err != nil {
 		return nil, fmt.Errorf("config validation failed: %w", err)
 	}
 
-	return &cfg, nil
+	return cfg, nil
 }
 
-// LoadWithFlags reads configuration with CLI flag overrides
+// LoadWithFlags reads configuration with CLI flag overrides. It returns
+// pflag.ErrHelp when --help was given.
 func LoadWithFlags() (*Config, error) {
 	// Define CLI flags
-	configFile := pflag.String("config", "config.yaml", "Path to config file")
+	configFile := pflag.String("config", "", "Path to config file (default: search ./, ~/.config/dmarc-viewer/, /etc/dmarc-viewer/)")
+	imapProvider := pflag.String("imap-provider", "", "IMAP provider preset (gmail, o365, fastmail, yahoo)")
 	imapHost := pflag.String("imap-host", "", "IMAP server host")
 	imapPort := pflag.Int("imap-port", 0, "IMAP server port")
 	imapUsername := pflag.String("imap-username", "", "IMAP username")
 	imapPassword := pflag.String("imap-password", "", "IMAP password")
 	imapFolder := pflag.String("imap-folder", "", "IMAP folder")
 	imapUseTLS := pflag.Bool("imap-use-tls", true, "Use TLS for IMAP connection")
+	imapTrace := pflag.Bool("imap-trace", false, "Write a redacted IMAP protocol trace to imap.trace.file")
 	databasePath := pflag.String("database", "", "Database file path")
 	webHost := pflag.String("web-host", "", "Web server host")
 	webPort := pflag.Int("web-port", 0, "Web server port")
@@ -102,27 +232,43 @@ func LoadWithFlags() (*Config, error) {
 	syncOnStartup := pflag.Bool("sync-on-startup", false, "Run sync on startup")
 	logLevel := pflag.String("log-level", "", "Log level (debug, info, warn, error)")
 	logFormat := pflag.String("log-format", "", "Log format (json, text)")
-
-	pflag.Parse()
+	logOutput := pflag.String("log-output", "", "Log output (stdout, file, syslog)")
+
+	// Report bad flags to the caller rather than exiting with the flag
+	// package's status 2, which would read as a connection failure
+	pflag.CommandLine.Init(os.Args[0], pflag.ContinueOnError)
+	if err := pflag.CommandLine.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("invalid flags: %w", err)
+	}
 
 	v := viper.New()
 
 	// Set default values
 	setDefaults(v)
 
-	// Read from config file
-	if *configFile != "" {
-		v.SetConfigFile(*configFile)
-		// Ignore error if config file doesn't exist
-		_ = v.ReadInConfig()
+	// Read from config file, searching the standard locations if none was
+	// given. An explicit --config must exist, as with Load.
+	file := *configFile
+	if file == "" {
+		file = FindConfigFile()
+	}
+	if file != "" {
+		v.SetConfigFile(file)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
 	}
 
 	// Read from environment variables
-	v.SetEnvPrefix("DMARC")
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	v.AutomaticEnv()
+	readEnv(v)
 
 	// Override with CLI flags (highest priority)
+	if pflag.Lookup("imap-provider").Changed {
+		v.Set("imap.provider", *imapProvider)
+	}
 	if pflag.Lookup("imap-host").Changed {
 		v.Set("imap.host", *imapHost)
 	}
@@ -141,6 +287,9 @@ func LoadWithFlags() (*Config, error) {
 	if pflag.Lookup("imap-use-tls").Changed {
 		v.Set("imap.use_tls", *imapUseTLS)
 	}
+	if pflag.Lookup("imap-trace").Changed {
+		v.Set("imap.trace.enabled", *imapTrace)
+	}
 	if pflag.Lookup("database").Changed {
 		v.Set("database.path", *databasePath)
 	}
@@ -162,13 +311,73 @@ func LoadWithFlags() (*Config, error) {
 	if pflag.Lookup("log-format").Changed {
 		v.Set("logging.format", *logFormat)
 	}
+	if pflag.Lookup("log-output").Changed {
+		v.Set("logging.output", *logOutput)
+	}
+
+	// Fill in connection settings for a known provider
+	applyProviderPreset(v)
 
 	// Unmarshal into Config struct
+	cfg, err := unmarshal(v)
+	if err != nil {
+		return nil, err
+	}
+	cfg.File = file
+
+	return cfg, nil
+}
+
+// readEnv makes viper read DMARC_-prefixed environment variables. Every
+// setting is bound explicitly, because Unmarshal only looks up the
+// environment for keys viper already knows, and those without a default,
+// such as privacy.hash_key, would otherwise be ignored.
+func readEnv(v *viper.Viper) {
+	v.SetEnvPrefix("DMARC")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	bindEnv(v, reflect.TypeOf(Config{}), "")
+}
+
+// bindEnv binds the environment variable of every setting in t, found at the
+// given dotted key. Maps such as logging.levels can't be set from a single
+// variable and are left to the config file.
+func bindEnv(v *viper.Viper, t reflect.Type, key string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		childKey := name
+		if key != "" {
+			childKey = key + "." + name
+		}
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			bindEnv(v, field.Type, childKey)
+		case reflect.Map:
+		default:
+			v.BindEnv(childKey)
+		}
+	}
+}
+
+// unmarshal decodes the merged viper settings into a Config, matching keys
+// against the yaml struct tags so multi-word keys such as use_tls are honored
+func unmarshal(v *viper.Viper) (*Config, error) {
 	var cfg Config
-	if err := v.Unmarshal(&cfg); err != nil {
+	if err := v.Unmarshal(&cfg, func(dc *mapstructure.DecoderConfig) {
+		dc.TagName = "yaml"
+	}); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	// envelope_addresses used to cover envelope_to as well, so an existing
+	// "drop" or "domain" must keep applying to recipients until they're set
+	if cfg.Privacy.Recipients == "" {
+		cfg.Privacy.Recipients = cfg.Privacy.EnvelopeAddresses
+	}
 	return &cfg, nil
 }
 
@@ -178,6 +387,10 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("imap.port", 993)
 	v.SetDefault("imap.folder", "INBOX")
 	v.SetDefault("imap.use_tls", true)
+	v.SetDefault("imap.circuit_breaker.failure_threshold", 5)
+	v.SetDefault("imap.circuit_breaker.cooldown", "10m")
+	v.SetDefault("imap.trace.enabled", false)
+	v.SetDefault("imap.trace.file", "./imap-trace.log")
 
 	// Database defaults
 	v.SetDefault("database.path", "./dmarc-reports.db")
@@ -193,34 +406,326 @@ func setDefaults(v *viper.Viper) {
 	// Logging defaults
 	v.SetDefault("logging.level", "info")
 	v.SetDefault("logging.format", "text")
+	v.SetDefault("logging.output", "stdout")
+	v.SetDefault("logging.file.path", "./dmarc-viewer.log")
+	v.SetDefault("logging.file.max_size_mb", 100)
+	v.SetDefault("logging.file.max_backups", 7)
+	v.SetDefault("logging.file.compress", true)
+
+	// Privacy defaults
+	v.SetDefault("privacy.source_ip", "keep")
+	v.SetDefault("privacy.envelope_addresses", "keep")
+	v.SetDefault("privacy.ipv4_prefix", 24)
+	v.SetDefault("privacy.ipv6_prefix", 64)
+
+	// DNS defaults
+	v.SetDefault("dns.protocol", "udp")
+	v.SetDefault("dns.timeout", "5s")
+	v.SetDefault("dns.concurrency", 10)
+
+	// Retry defaults
+	v.SetDefault("retry.dns.max_attempts", 3)
+	v.SetDefault("retry.dns.initial_interval", "200ms")
+	v.SetDefault("retry.dns.max_interval", "2s")
+	v.SetDefault("retry.dns.multiplier", 2.0)
+	v.SetDefault("retry.dns.jitter", 0.2)
+}
+
+// allowedValues lists the accepted values of enumerated settings, shared by
+// validate and the JSON Schema output
+var allowedValues = map[string][]string{
+	"imap.provider":              {"gmail", "o365", "fastmail", "yahoo"},
+	"logging.level":              {"debug", "info", "warn", "error"},
+	"logging.format":             {"json", "text"},
+	"logging.output":             {"stdout", "file", "syslog"},
+	"logging.levels":             {"debug", "info", "warn", "error"},
+	"privacy.source_ip":          {"keep", "truncate", "hash"},
+	"privacy.envelope_addresses": {"keep", "domain", "drop"},
+	"privacy.recipients":         {"keep", "hash", "domain", "drop"},
+	"dns.protocol":               {"udp", "tcp", "tls"},
+}
+
+// isAllowed reports whether value is one of the accepted values for key
+func isAllowed(key, value string) bool {
+	return slices.Contains(allowedValues[key], value)
+}
+
+// Validate checks a loaded configuration, e.g., one returned by LoadWithFlags
+func Validate(cfg *Config) error {
+	return validate(cfg)
+}
+
+// CheckDatabasePath reports whether the database file at path can be written,
+// or created if it doesn't exist yet
+func CheckDatabasePath(path string) error {
+	return validateWritable("database.path", path, false)
+}
+
+// CheckOutputPaths reports whether the log file and the IMAP trace file can
+// be written, when those outputs are enabled
+func CheckOutputPaths(cfg *Config) error {
+	var errs []error
+	if cfg.Logging.Output == "file" && cfg.Logging.File.Path != "" {
+		errs = append(errs, validateWritable("logging.file.path", cfg.Logging.File.Path, true))
+	}
+	if cfg.IMAP.Trace.Enabled && cfg.IMAP.Trace.File != "" {
+		errs = append(errs, validateWritable("imap.trace.file", cfg.IMAP.Trace.File, true))
+	}
+	return errors.Join(errs...)
 }
 
-// validate checks that required configuration fields are set
+// CheckCAFile reports whether the configured IMAP CA bundle can be read and
+// contains at least one certificate
+func CheckCAFile(cfg *Config) error {
+	if cfg.IMAP.TLS.CAFile == "" {
+		return nil
+	}
+	pem, err := os.ReadFile(cfg.IMAP.TLS.CAFile)
+	if err != nil {
+		return fmt.Errorf("failed to read imap.tls.ca_file: %w", err)
+	}
+	if !x509.NewCertPool().AppendCertsFromPEM(pem) {
+		return fmt.Errorf("no certificates found in imap.tls.ca_file %s", cfg.IMAP.TLS.CAFile)
+	}
+	return nil
+}
+
+// ParseFingerprint decodes a hex SHA-256 certificate fingerprint, accepting
+// the colon separated form printed by openssl
+func ParseFingerprint(fp string) ([]byte, error) {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(fp), ":", ""))
+	pin, err := hex.DecodeString(normalized)
+	if err != nil || len(pin) != sha256.Size {
+		return nil, fmt.Errorf("invalid imap.tls.fingerprints entry: %s (must be a hex SHA-256 digest)", fp)
+	}
+	return pin, nil
+}
+
+// validate checks the configuration and reports every problem found rather
+// than stopping at the first one. It doesn't touch the filesystem; the
+// CheckDatabasePath, CheckOutputPaths and CheckCAFile probes are left to
+// check-config and doctor.
 func validate(cfg *Config) error {
+	var errs []error
+
+	if cfg.IMAP.Provider != "" && !isAllowed("imap.provider", cfg.IMAP.Provider) {
+		errs = append(errs, fmt.Errorf("invalid imap.provider: %s (must be gmail, o365, fastmail, or yahoo)", cfg.IMAP.Provider))
+	}
 	if cfg.IMAP.Host == "" {
-		return fmt.Errorf("imap.host is required")
+		errs = append(errs, fmt.Errorf("imap.host is required"))
 	}
 	if cfg.IMAP.Username == "" {
-		return fmt.Errorf("imap.username is required")
+		errs = append(errs, fmt.Errorf("imap.username is required"))
 	}
 	if cfg.IMAP.Password == "" {
-		return fmt.Errorf("imap.password is required")
+		errs = append(errs, fmt.Errorf("imap.password is required"))
 	}
 	if cfg.Database.Path == "" {
-		return fmt.Errorf("database.path is required")
+		errs = append(errs, fmt.Errorf("database.path is required"))
 	}
 
 	// Validate log level
-	validLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
-	if !validLevels[cfg.Logging.Level] {
-		return fmt.Errorf("invalid log level: %s (must be debug, info, warn, or error)", cfg.Logging.Level)
+	if !isAllowed("logging.level", cfg.Logging.Level) {
+		errs = append(errs, fmt.Errorf("invalid log level: %s (must be debug, info, warn, or error)", cfg.Logging.Level))
 	}
 
 	// Validate log format
-	validFormats := map[string]bool{"json": true, "text": true}
-	if !validFormats[cfg.Logging.Format] {
-		return fmt.Errorf("invalid log format: %s (must be json or text)", cfg.Logging.Format)
+	if !isAllowed("logging.format", cfg.Logging.Format) {
+		errs = append(errs, fmt.Errorf("invalid log format: %s (must be json or text)", cfg.Logging.Format))
 	}
 
+	// Validate per-component log levels, in key order so errors are stable
+	for _, component := range slices.Sorted(maps.Keys(cfg.Logging.Levels)) {
+		if level := cfg.Logging.Levels[component]; !isAllowed("logging.levels", level) {
+			errs = append(errs, fmt.Errorf("invalid logging.levels.%s: %s (must be debug, info, warn, or error)", component, level))
+		}
+	}
+
+	// Validate log output
+	// An empty output is treated as stdout
+	if cfg.Logging.Output != "" && !isAllowed("logging.output", cfg.Logging.Output) {
+		errs = append(errs, fmt.Errorf("invalid logging.output: %s (must be stdout, file, or syslog)", cfg.Logging.Output))
+	}
+	if cfg.Logging.Output == "file" {
+		file := cfg.Logging.File
+		if file.Path == "" {
+			errs = append(errs, fmt.Errorf("logging.file.path is required when logging.output is file"))
+		}
+		if file.MaxSizeMB < 0 {
+			errs = append(errs, fmt.Errorf("invalid logging.file.max_size_mb: %d (must not be negative)", file.MaxSizeMB))
+		}
+		if file.MaxBackups < 0 {
+			errs = append(errs, fmt.Errorf("invalid logging.file.max_backups: %d (must not be negative)", file.MaxBackups))
+		}
+		if file.RotateEvery != "" {
+			errs = append(errs, validateDuration("logging.file.rotate_every", file.RotateEvery))
+		}
+		if file.MaxAge != "" {
+			errs = append(errs, validateDuration("logging.file.max_age", file.MaxAge))
+		}
+	}
+
+	// Validate privacy settings
+	// An empty mode is treated as keep
+	if cfg.Privacy.SourceIP != "" && !isAllowed("privacy.source_ip", cfg.Privacy.SourceIP) {
+		errs = append(errs, fmt.Errorf("invalid privacy.source_ip: %s (must be keep, truncate, or hash)", cfg.Privacy.SourceIP))
+	}
+	if cfg.Privacy.SourceIP == "hash" && cfg.Privacy.HashKey == "" {
+		errs = append(errs, fmt.Errorf("privacy.hash_key is required when privacy.source_ip is hash"))
+	}
+	if cfg.Privacy.SourceIP == "truncate" {
+		if cfg.Privacy.IPv4Prefix < 0 || cfg.Privacy.IPv4Prefix > 32 {
+			errs = append(errs, fmt.Errorf("invalid privacy.ipv4_prefix: %d (must be between 0 and 32)", cfg.Privacy.IPv4Prefix))
+		}
+		if cfg.Privacy.IPv6Prefix < 0 || cfg.Privacy.IPv6Prefix > 128 {
+			errs = append(errs, fmt.Errorf("invalid privacy.ipv6_prefix: %d (must be between 0 and 128)", cfg.Privacy.IPv6Prefix))
+		}
+	}
+	if cfg.Privacy.EnvelopeAddresses != "" && !isAllowed("privacy.envelope_addresses", cfg.Privacy.EnvelopeAddresses) {
+		errs = append(errs, fmt.Errorf("invalid privacy.envelope_addresses: %s (must be keep, domain, or drop)", cfg.Privacy.EnvelopeAddresses))
+	}
+	if cfg.Privacy.Recipients != "" && !isAllowed("privacy.recipients", cfg.Privacy.Recipients) {
+		errs = append(errs, fmt.Errorf("invalid privacy.recipients: %s (must be keep, hash, domain, or drop)", cfg.Privacy.Recipients))
+	}
+	if cfg.Privacy.Recipients == "hash" && cfg.Privacy.HashKey == "" {
+		errs = append(errs, fmt.Errorf("privacy.hash_key is required when privacy.recipients is hash"))
+	}
+
+	// Validate DNS settings
+	if cfg.DNS.Protocol != "" && !isAllowed("dns.protocol", cfg.DNS.Protocol) {
+		errs = append(errs, fmt.Errorf("invalid dns.protocol: %s (must be udp, tcp, or tls)", cfg.DNS.Protocol))
+	}
+	if cfg.DNS.Protocol == "tls" && cfg.DNS.Server == "" {
+		errs = append(errs, fmt.Errorf("dns.server is required when dns.protocol is tls"))
+	}
+	if cfg.DNS.Concurrency < 0 {
+		errs = append(errs, fmt.Errorf("invalid dns.concurrency: %d (must not be negative)", cfg.DNS.Concurrency))
+	}
+
+	// Validate ports
+	errs = append(errs,
+		validatePort("imap.port", cfg.IMAP.Port),
+		validatePort("web.port", cfg.Web.Port),
+	)
+
+	// Validate durations
+	errs = append(errs, validateDuration("sync.interval", cfg.Sync.Interval))
+	if cfg.DNS.Timeout != "" {
+		errs = append(errs, validateDuration("dns.timeout", cfg.DNS.Timeout))
+	}
+
+	// Validate the IMAP trace file
+	if cfg.IMAP.Trace.Enabled && cfg.IMAP.Trace.File == "" {
+		errs = append(errs, fmt.Errorf("imap.trace.file is required when imap.trace.enabled is true"))
+	}
+
+	// Validate the pinned IMAP certificate fingerprints
+	for _, fp := range cfg.IMAP.TLS.Fingerprints {
+		if _, err := ParseFingerprint(fp); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Validate the IMAP circuit breaker
+	if cfg.IMAP.CircuitBreaker.FailureThreshold < 0 {
+		errs = append(errs, fmt.Errorf("invalid imap.circuit_breaker.failure_threshold: %d (must not be negative)", cfg.IMAP.CircuitBreaker.FailureThreshold))
+	}
+	if cfg.IMAP.CircuitBreaker.FailureThreshold > 0 {
+		errs = append(errs, validateDuration("imap.circuit_breaker.cooldown", cfg.IMAP.CircuitBreaker.Cooldown))
+	}
+
+	// Validate retry policies
+	errs = append(errs, validateRetryPolicy("retry.dns", cfg.Retry.DNS))
+
+	// errors.Join drops the nil results of the helpers above
+	return errors.Join(errs...)
+}
+
+// validatePort checks that a port number is in the valid TCP range
+func validatePort(key string, port int) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("invalid %s: %d (must be between 1 and 65535)", key, port)
+	}
+	return nil
+}
+
+// validateDuration checks that a setting parses as a positive duration
+func validateDuration(key, value string) error {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return fmt.Errorf("invalid %s: %q (must be a positive duration such as 15m)", key, value)
+	}
 	return nil
 }
+
+// validateWritable checks that the file at path can be written, or created
+// if it doesn't exist yet, without modifying an existing file. With
+// createDirs, missing parent directories are accepted as long as the nearest
+// existing one is writable, for outputs whose opener runs MkdirAll.
+func validateWritable(key, path string, createDirs bool) error {
+	if path == ":memory:" {
+		return nil
+	}
+
+	if info, err := os.Stat(path); err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("invalid %s: %s is a directory", key, path)
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %s is not writable: %w", key, path, err)
+		}
+		return f.Close()
+	}
+
+	dir := filepath.Dir(path)
+	if createDirs {
+		dir = nearestExistingDir(dir)
+	}
+	f, err := os.CreateTemp(dir, ".dmarc-viewer-check-*")
+	if err != nil {
+		return fmt.Errorf("invalid %s: cannot create files in %s: %w", key, dir, err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// nearestExistingDir returns dir or its closest ancestor that exists
+func nearestExistingDir(dir string) string {
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+// validateRetryPolicy checks a retry policy. An entirely unset policy is
+// allowed and means the operation is tried once.
+func validateRetryPolicy(key string, p RetryPolicyConfig) error {
+	if p == (RetryPolicyConfig{}) {
+		return nil
+	}
+
+	var errs []error
+	if p.MaxAttempts < 1 {
+		errs = append(errs, fmt.Errorf("invalid %s.max_attempts: %d (must be at least 1)", key, p.MaxAttempts))
+	}
+	if p.MaxAttempts > 1 {
+		errs = append(errs,
+			validateDuration(key+".initial_interval", p.InitialInterval),
+			validateDuration(key+".max_interval", p.MaxInterval),
+		)
+	}
+	if p.Multiplier < 1 {
+		errs = append(errs, fmt.Errorf("invalid %s.multiplier: %g (must be at least 1)", key, p.Multiplier))
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		errs = append(errs, fmt.Errorf("invalid %s.jitter: %g (must be between 0 and 1)", key, p.Jitter))
+	}
+	return errors.Join(errs...)
+}
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/spf13/pflag"
@@ -116,6 +119,32 @@ logging:
 	}
 }
 
+func TestLoad_EnvironmentOnlySettings(t *testing.T) {
+	// Settings without a default must still be read from the environment
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `
+imap:
+  host: imap.test.com
+  username: test@test.com
+  password: testpass
+privacy:
+  source_ip: hash
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	t.Setenv("DMARC_PRIVACY_HASH_KEY", "env-secret")
+
+	cfg, err := Load(configFile)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Privacy.HashKey != "env-secret" {
+		t.Errorf("Expected hash key from env 'env-secret', got '%s'", cfg.Privacy.HashKey)
+	}
+}
+
 func TestLoad_MissingRequiredFields(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -254,6 +283,280 @@ logging:
 	}
 }
 
+func TestLoad_RecipientsFollowEnvelopeAddresses(t *testing.T) {
+	tests := []struct {
+		name     string
+		privacy  string
+		env      map[string]string
+		expected string
+	}{
+		{"old-style config", "  envelope_addresses: drop\n", nil, "drop"},
+		{"domain only", "  envelope_addresses: domain\n", nil, "domain"},
+		{"explicit recipients", "  envelope_addresses: drop\n  recipients: keep\n", nil, "keep"},
+		{"neither set", "", nil, "keep"},
+		{"recipients from env", "  envelope_addresses: drop\n", map[string]string{"DMARC_PRIVACY_RECIPIENTS": "domain"}, "domain"},
+		{"envelope addresses from env", "  source_ip: keep\n", map[string]string{"DMARC_PRIVACY_ENVELOPE_ADDRESSES": "drop"}, "drop"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			configFile := filepath.Join(t.TempDir(), "config.yaml")
+			configContent := `
+imap:
+  host: imap.test.com
+  username: test@test.com
+  password: testpass
+privacy:
+` + tt.privacy
+			if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+				t.Fatalf("Failed to create config file: %v", err)
+			}
+
+			cfg, err := Load(configFile)
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if cfg.Privacy.Recipients != tt.expected {
+				t.Errorf("Expected privacy recipients '%s', got '%s'", tt.expected, cfg.Privacy.Recipients)
+			}
+		})
+	}
+}
+
+func TestLoad_RecipientsHashFromEnvironment(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `
+imap:
+  host: imap.test.com
+  username: test@test.com
+  password: testpass
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	t.Setenv("DMARC_PRIVACY_RECIPIENTS", "hash")
+
+	_, err := Load(configFile)
+	expected := "privacy.hash_key is required when privacy.recipients is hash"
+	if err == nil || !strings.Contains(err.Error(), expected) {
+		t.Errorf("Expected error containing '%s', got: %v", expected, err)
+	}
+}
+
+func TestLoad_LogLevels(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+
+	configContent := `
+imap:
+  host: imap.test.com
+  username: test@test.com
+  password: testpass
+logging:
+  level: info
+  levels:
+    imap: debug
+    web: warn
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	cfg, err := Load(configFile)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Logging.Levels["imap"] != "debug" {
+		t.Errorf("Expected imap log level 'debug', got '%s'", cfg.Logging.Levels["imap"])
+	}
+	if cfg.Logging.Levels["web"] != "warn" {
+		t.Errorf("Expected web log level 'warn', got '%s'", cfg.Logging.Levels["web"])
+	}
+
+	cfg.Logging.Levels["dns"] = "verbose"
+	if err := validate(cfg); err == nil || !strings.Contains(err.Error(), "invalid logging.levels.dns: verbose") {
+		t.Errorf("Expected invalid component level error, got: %v", err)
+	}
+}
+
+func TestLoad_MultiWordKeys(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+
+	configContent := `
+imap:
+  host: imap.test.com
+  username: test@test.com
+  password: testpass
+  use_tls: false
+sync:
+  on_startup: false
+privacy:
+  source_ip: truncate
+  envelope_addresses: domain
+  ipv4_prefix: 16
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	cfg, err := Load(configFile)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.IMAP.UseTLS {
+		t.Error("Expected IMAP use_tls false from YAML, got true")
+	}
+	if cfg.Sync.OnStartup {
+		t.Error("Expected sync on_startup false from YAML, got true")
+	}
+	if cfg.Privacy.SourceIP != "truncate" {
+		t.Errorf("Expected privacy source_ip 'truncate', got '%s'", cfg.Privacy.SourceIP)
+	}
+	if cfg.Privacy.EnvelopeAddresses != "domain" {
+		t.Errorf("Expected privacy envelope_addresses 'domain', got '%s'", cfg.Privacy.EnvelopeAddresses)
+	}
+	if cfg.Privacy.IPv4Prefix != 16 {
+		t.Errorf("Expected privacy ipv4_prefix 16, got %d", cfg.Privacy.IPv4Prefix)
+	}
+	if cfg.Privacy.IPv6Prefix != 64 {
+		t.Errorf("Expected default privacy ipv6_prefix 64, got %d", cfg.Privacy.IPv6Prefix)
+	}
+}
+
+func TestLoad_IMAPTLSConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+
+	configContent := `
+imap:
+  host: imap.test.com
+  username: test@test.com
+  password: testpass
+  tls:
+    ca_file: /etc/ssl/private-ca.pem
+    insecure_skip_verify: true
+    fingerprints:
+      - "ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab"
+      - "cdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcd"
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	cfg, err := Load(configFile)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.IMAP.TLS.CAFile != "/etc/ssl/private-ca.pem" {
+		t.Errorf("Expected TLS CA file '/etc/ssl/private-ca.pem', got '%s'", cfg.IMAP.TLS.CAFile)
+	}
+	if !cfg.IMAP.TLS.InsecureSkipVerify {
+		t.Error("Expected TLS insecure_skip_verify true, got false")
+	}
+	if len(cfg.IMAP.TLS.Fingerprints) != 2 || cfg.IMAP.TLS.Fingerprints[0] != "ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab:ab" {
+		t.Errorf("Expected 2 TLS fingerprints, got %v", cfg.IMAP.TLS.Fingerprints)
+	}
+}
+
+func TestLoad_OtherFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name: "TOML",
+			file: "config.toml",
+			content: `
+[imap]
+host = "imap.test.com"
+username = "test@test.com"
+password = "testpass"
+use_tls = false
+
+[web]
+port = 9090
+`,
+		},
+		{
+			name: "JSON",
+			file: "config.json",
+			content: `{
+  "imap": {
+    "host": "imap.test.com",
+    "username": "test@test.com",
+    "password": "testpass",
+    "use_tls": false
+  },
+  "web": {"port": 9090}
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(configFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create config file: %v", err)
+			}
+
+			cfg, err := Load(configFile)
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+
+			if cfg.IMAP.Host != "imap.test.com" {
+				t.Errorf("Expected IMAP host 'imap.test.com', got '%s'", cfg.IMAP.Host)
+			}
+			if cfg.IMAP.UseTLS {
+				t.Error("Expected IMAP use_tls false, got true")
+			}
+			if cfg.Web.Port != 9090 {
+				t.Errorf("Expected web port 9090, got %d", cfg.Web.Port)
+			}
+			if cfg.File != configFile {
+				t.Errorf("Expected File '%s', got '%s'", configFile, cfg.File)
+			}
+		})
+	}
+}
+
+func TestFindConfigFile(t *testing.T) {
+	first := t.TempDir()
+	second := t.TempDir()
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	if got := findConfigFile([]string{first, second}); got != "" {
+		t.Errorf("Expected no config file, got '%s'", got)
+	}
+
+	secondFile := filepath.Join(second, "config.toml")
+	if err := os.WriteFile(secondFile, []byte(""), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+	if got := findConfigFile([]string{missing, first, second}); got != secondFile {
+		t.Errorf("Expected '%s', got '%s'", secondFile, got)
+	}
+
+	// Earlier directories win, and YAML is preferred within a directory
+	for _, name := range []string{"config.json", "config.yaml"} {
+		if err := os.WriteFile(filepath.Join(first, name), []byte(""), 0644); err != nil {
+			t.Fatalf("Failed to create config file: %v", err)
+		}
+	}
+	if got := findConfigFile([]string{first, second}); got != filepath.Join(first, "config.yaml") {
+		t.Errorf("Expected '%s', got '%s'", filepath.Join(first, "config.yaml"), got)
+	}
+}
+
 func TestLoad_DefaultValues(t *testing.T) {
 	tmpDir := t.TempDir()
 	configFile := filepath.Join(tmpDir, "config.yaml")
@@ -280,16 +583,15 @@ logging:
 	}
 
 	// Check default values for fields not specified in YAML
-	// Note: boolean fields default to false when parent section is present in YAML
-	// This is a limitation of YAML unmarshaling behavior
 	if cfg.IMAP.Port != 993 {
 		t.Errorf("Expected default IMAP port 993, got %d", cfg.IMAP.Port)
 	}
 	if cfg.IMAP.Folder != "INBOX" {
 		t.Errorf("Expected default IMAP folder 'INBOX', got '%s'", cfg.IMAP.Folder)
 	}
-	// UseTLS defaults to false when imap section exists but field not specified
-	// This is expected behavior with YAML unmarshaling
+	if !cfg.IMAP.UseTLS {
+		t.Error("Expected default IMAP use_tls true, got false")
+	}
 
 	if cfg.Database.Path != "./dmarc-reports.db" {
 		t.Errorf("Expected default database path './dmarc-reports.db', got '%s'", cfg.Database.Path)
@@ -303,8 +605,9 @@ logging:
 	if cfg.Sync.Interval != "15m" {
 		t.Errorf("Expected default sync interval '15m', got '%s'", cfg.Sync.Interval)
 	}
-	// OnStartup defaults to false when sync section doesn't exist in YAML
-	// This is expected behavior with YAML unmarshaling
+	if !cfg.Sync.OnStartup {
+		t.Error("Expected default sync on_startup true, got false")
+	}
 
 	if cfg.Logging.Level != "info" {
 		t.Errorf("Expected default log level 'info', got '%s'", cfg.Logging.Level)
@@ -332,6 +635,13 @@ func TestSetDefaults(t *testing.T) {
 		{"sync.on_startup", true},
 		{"logging.level", "info"},
 		{"logging.format", "text"},
+		{"privacy.source_ip", "keep"},
+		{"privacy.envelope_addresses", "keep"},
+		{"privacy.ipv4_prefix", 24},
+		{"privacy.ipv6_prefix", 64},
+		{"dns.protocol", "udp"},
+		{"dns.timeout", "5s"},
+		{"dns.concurrency", 10},
 	}
 
 	for _, tt := range tests {
@@ -356,12 +666,19 @@ func TestValidate(t *testing.T) {
 			config: Config{
 				IMAP: IMAPConfig{
 					Host:     "imap.test.com",
+					Port:     993,
 					Username: "test@test.com",
 					Password: "testpass",
 				},
 				Database: DatabaseConfig{
 					Path: "./test.db",
 				},
+				Web: WebConfig{
+					Port: 8080,
+				},
+				Sync: SyncConfig{
+					Interval: "15m",
+				},
 				Logging: LogConfig{
 					Level:  "info",
 					Format: "text",
@@ -373,12 +690,19 @@ func TestValidate(t *testing.T) {
 			name: "missing host",
 			config: Config{
 				IMAP: IMAPConfig{
+					Port:     993,
 					Username: "test@test.com",
 					Password: "testpass",
 				},
 				Database: DatabaseConfig{
 					Path: "./test.db",
 				},
+				Web: WebConfig{
+					Port: 8080,
+				},
+				Sync: SyncConfig{
+					Interval: "15m",
+				},
 				Logging: LogConfig{
 					Level:  "info",
 					Format: "text",
@@ -392,12 +716,19 @@ func TestValidate(t *testing.T) {
 			config: Config{
 				IMAP: IMAPConfig{
 					Host:     "imap.test.com",
+					Port:     993,
 					Username: "test@test.com",
 					Password: "testpass",
 				},
 				Database: DatabaseConfig{
 					Path: "./test.db",
 				},
+				Web: WebConfig{
+					Port: 8080,
+				},
+				Sync: SyncConfig{
+					Interval: "15m",
+				},
 				Logging: LogConfig{
 					Level:  "invalid",
 					Format: "text",
@@ -411,12 +742,19 @@ func TestValidate(t *testing.T) {
 			config: Config{
 				IMAP: IMAPConfig{
 					Host:     "imap.test.com",
+					Port:     993,
 					Username: "test@test.com",
 					Password: "testpass",
 				},
 				Database: DatabaseConfig{
 					Path: "./test.db",
 				},
+				Web: WebConfig{
+					Port: 8080,
+				},
+				Sync: SyncConfig{
+					Interval: "15m",
+				},
 				Logging: LogConfig{
 					Level:  "info",
 					Format: "invalid",
@@ -425,27 +763,693 @@ func TestValidate(t *testing.T) {
 			wantError: true,
 			errorMsg:  "invalid log format: invalid (must be json or text)",
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			err := validate(&tt.config)
-			if tt.wantError {
-				if err == nil {
-					t.Error("Expected error, got nil")
-				} else if err.Error() != tt.errorMsg {
-					t.Errorf("Expected error '%s', got '%s'", tt.errorMsg, err.Error())
-				}
-			} else {
-				if err != nil {
-					t.Errorf("Expected no error, got: %v", err)
-				}
-			}
-		})
-	}
-}
-
-// Reset pflag for testing
-func resetFlags() {
-	pflag.CommandLine = pflag.NewFlagSet(os.Args[0], pflag.ExitOnError)
+		{
+			name: "invalid privacy source_ip mode",
+			config: Config{
+				IMAP: IMAPConfig{
+					Host:     "imap.test.com",
+					Port:     993,
+					Username: "test@test.com",
+					Password: "testpass",
+				},
+				Database: DatabaseConfig{
+					Path: "./test.db",
+				},
+				Web: WebConfig{
+					Port: 8080,
+				},
+				Sync: SyncConfig{
+					Interval: "15m",
+				},
+				Logging: LogConfig{
+					Level:  "info",
+					Format: "text",
+				},
+				Privacy: PrivacyConfig{
+					SourceIP: "scramble",
+				},
+			},
+			wantError: true,
+			errorMsg:  "invalid privacy.source_ip: scramble (must be keep, truncate, or hash)",
+		},
+		{
+			name: "privacy hash without key",
+			config: Config{
+				IMAP: IMAPConfig{
+					Host:     "imap.test.com",
+					Port:     993,
+					Username: "test@test.com",
+					Password: "testpass",
+				},
+				Database: DatabaseConfig{
+					Path: "./test.db",
+				},
+				Web: WebConfig{
+					Port: 8080,
+				},
+				Sync: SyncConfig{
+					Interval: "15m",
+				},
+				Logging: LogConfig{
+					Level:  "info",
+					Format: "text",
+				},
+				Privacy: PrivacyConfig{
+					SourceIP: "hash",
+				},
+			},
+			wantError: true,
+			errorMsg:  "privacy.hash_key is required when privacy.source_ip is hash",
+		},
+		{
+			name: "privacy truncate prefix out of range",
+			config: Config{
+				IMAP: IMAPConfig{
+					Host:     "imap.test.com",
+					Port:     993,
+					Username: "test@test.com",
+					Password: "testpass",
+				},
+				Database: DatabaseConfig{
+					Path: "./test.db",
+				},
+				Web: WebConfig{
+					Port: 8080,
+				},
+				Sync: SyncConfig{
+					Interval: "15m",
+				},
+				Logging: LogConfig{
+					Level:  "info",
+					Format: "text",
+				},
+				Privacy: PrivacyConfig{
+					SourceIP:   "truncate",
+					IPv4Prefix: 33,
+					IPv6Prefix: 64,
+				},
+			},
+			wantError: true,
+			errorMsg:  "invalid privacy.ipv4_prefix: 33 (must be between 0 and 32)",
+		},
+		{
+			name: "invalid privacy envelope_addresses mode",
+			config: Config{
+				IMAP: IMAPConfig{
+					Host:     "imap.test.com",
+					Port:     993,
+					Username: "test@test.com",
+					Password: "testpass",
+				},
+				Database: DatabaseConfig{
+					Path: "./test.db",
+				},
+				Web: WebConfig{
+					Port: 8080,
+				},
+				Sync: SyncConfig{
+					Interval: "15m",
+				},
+				Logging: LogConfig{
+					Level:  "info",
+					Format: "text",
+				},
+				Privacy: PrivacyConfig{
+					EnvelopeAddresses: "hash",
+				},
+			},
+			wantError: true,
+			errorMsg:  "invalid privacy.envelope_addresses: hash (must be keep, domain, or drop)",
+		},
+		{
+			name: "privacy recipients hash without key",
+			config: Config{
+				IMAP: IMAPConfig{
+					Host:     "imap.test.com",
+					Port:     993,
+					Username: "test@test.com",
+					Password: "testpass",
+				},
+				Database: DatabaseConfig{
+					Path: "./test.db",
+				},
+				Web: WebConfig{
+					Port: 8080,
+				},
+				Sync: SyncConfig{
+					Interval: "15m",
+				},
+				Logging: LogConfig{
+					Level:  "info",
+					Format: "text",
+				},
+				Privacy: PrivacyConfig{
+					Recipients: "hash",
+				},
+			},
+			wantError: true,
+			errorMsg:  "privacy.hash_key is required when privacy.recipients is hash",
+		},
+		{
+			name: "invalid dns protocol",
+			config: Config{
+				IMAP: IMAPConfig{
+					Host:     "imap.test.com",
+					Port:     993,
+					Username: "test@test.com",
+					Password: "testpass",
+				},
+				Database: DatabaseConfig{
+					Path: "./test.db",
+				},
+				Web: WebConfig{
+					Port: 8080,
+				},
+				Sync: SyncConfig{
+					Interval: "15m",
+				},
+				Logging: LogConfig{
+					Level:  "info",
+					Format: "text",
+				},
+				DNS: DNSConfig{
+					Protocol: "https",
+				},
+			},
+			wantError: true,
+			errorMsg:  "invalid dns.protocol: https (must be udp, tcp, or tls)",
+		},
+		{
+			name: "dns over tls without server",
+			config: Config{
+				IMAP: IMAPConfig{
+					Host:     "imap.test.com",
+					Port:     993,
+					Username: "test@test.com",
+					Password: "testpass",
+				},
+				Database: DatabaseConfig{
+					Path: "./test.db",
+				},
+				Web: WebConfig{
+					Port: 8080,
+				},
+				Sync: SyncConfig{
+					Interval: "15m",
+				},
+				Logging: LogConfig{
+					Level:  "info",
+					Format: "text",
+				},
+				DNS: DNSConfig{
+					Protocol: "tls",
+				},
+			},
+			wantError: true,
+			errorMsg:  "dns.server is required when dns.protocol is tls",
+		},
+		{
+			name: "web port out of range",
+			config: Config{
+				IMAP: IMAPConfig{
+					Host:     "imap.test.com",
+					Port:     993,
+					Username: "test@test.com",
+					Password: "testpass",
+				},
+				Database: DatabaseConfig{
+					Path: "./test.db",
+				},
+				Web: WebConfig{
+					Port: 70000,
+				},
+				Sync: SyncConfig{
+					Interval: "15m",
+				},
+				Logging: LogConfig{
+					Level:  "info",
+					Format: "text",
+				},
+			},
+			wantError: true,
+			errorMsg:  "invalid web.port: 70000 (must be between 1 and 65535)",
+		},
+		{
+			name: "invalid sync interval",
+			config: Config{
+				IMAP: IMAPConfig{
+					Host:     "imap.test.com",
+					Port:     993,
+					Username: "test@test.com",
+					Password: "testpass",
+				},
+				Database: DatabaseConfig{
+					Path: "./test.db",
+				},
+				Web: WebConfig{
+					Port: 8080,
+				},
+				Sync: SyncConfig{
+					Interval: "15 minutes",
+				},
+				Logging: LogConfig{
+					Level:  "info",
+					Format: "text",
+				},
+			},
+			wantError: true,
+			errorMsg:  `invalid sync.interval: "15 minutes" (must be a positive duration such as 15m)`,
+		},
+		{
+			name: "file log output without path",
+			config: Config{
+				IMAP: IMAPConfig{
+					Host:     "imap.test.com",
+					Port:     993,
+					Username: "test@test.com",
+					Password: "testpass",
+				},
+				Database: DatabaseConfig{
+					Path: "./test.db",
+				},
+				Web: WebConfig{
+					Port: 8080,
+				},
+				Sync: SyncConfig{
+					Interval: "15m",
+				},
+				Logging: LogConfig{
+					Level:  "info",
+					Format: "text",
+					Output: "file",
+				},
+			},
+			wantError: true,
+			errorMsg:  "logging.file.path is required when logging.output is file",
+		},
+		{
+			name: "invalid log output",
+			config: Config{
+				IMAP: IMAPConfig{
+					Host:     "imap.test.com",
+					Port:     993,
+					Username: "test@test.com",
+					Password: "testpass",
+				},
+				Database: DatabaseConfig{
+					Path: "./test.db",
+				},
+				Web: WebConfig{
+					Port: 8080,
+				},
+				Sync: SyncConfig{
+					Interval: "15m",
+				},
+				Logging: LogConfig{
+					Level:  "info",
+					Format: "text",
+					Output: "papertrail",
+				},
+			},
+			wantError: true,
+			errorMsg:  "invalid logging.output: papertrail (must be stdout, file, or syslog)",
+		},
+		{
+			name: "imap trace without file",
+			config: Config{
+				IMAP: IMAPConfig{
+					Host:     "imap.test.com",
+					Port:     993,
+					Username: "test@test.com",
+					Password: "testpass",
+					Trace: IMAPTraceConfig{
+						Enabled: true,
+					},
+				},
+				Database: DatabaseConfig{
+					Path: "./test.db",
+				},
+				Web: WebConfig{
+					Port: 8080,
+				},
+				Sync: SyncConfig{
+					Interval: "15m",
+				},
+				Logging: LogConfig{
+					Level:  "info",
+					Format: "text",
+				},
+			},
+			wantError: true,
+			errorMsg:  "imap.trace.file is required when imap.trace.enabled is true",
+		},
+		{
+			name: "circuit breaker without cooldown",
+			config: Config{
+				IMAP: IMAPConfig{
+					Host:     "imap.test.com",
+					Port:     993,
+					Username: "test@test.com",
+					Password: "testpass",
+					CircuitBreaker: CircuitBreakerConfig{
+						FailureThreshold: 5,
+					},
+				},
+				Database: DatabaseConfig{
+					Path: "./test.db",
+				},
+				Web: WebConfig{
+					Port: 8080,
+				},
+				Sync: SyncConfig{
+					Interval: "15m",
+				},
+				Logging: LogConfig{
+					Level:  "info",
+					Format: "text",
+				},
+			},
+			wantError: true,
+			errorMsg:  `invalid imap.circuit_breaker.cooldown: "" (must be a positive duration such as 15m)`,
+		},
+		{
+			name: "invalid retry jitter",
+			config: Config{
+				IMAP: IMAPConfig{
+					Host:     "imap.test.com",
+					Port:     993,
+					Username: "test@test.com",
+					Password: "testpass",
+				},
+				Database: DatabaseConfig{
+					Path: "./test.db",
+				},
+				Web: WebConfig{
+					Port: 8080,
+				},
+				Sync: SyncConfig{
+					Interval: "15m",
+				},
+				Logging: LogConfig{
+					Level:  "info",
+					Format: "text",
+				},
+				Retry: RetryConfig{
+					DNS: RetryPolicyConfig{
+						MaxAttempts:     3,
+						InitialInterval: "200ms",
+						MaxInterval:     "2s",
+						Multiplier:      2,
+						Jitter:          1.5,
+					},
+				},
+			},
+			wantError: true,
+			errorMsg:  "invalid retry.dns.jitter: 1.5 (must be between 0 and 1)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate(&tt.config)
+			if tt.wantError {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				} else if err.Error() != tt.errorMsg {
+					t.Errorf("Expected error '%s', got '%s'", tt.errorMsg, err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate_ReportsAllProblems(t *testing.T) {
+	cfg := Config{
+		IMAP: IMAPConfig{
+			Port: 99999,
+			TLS:  IMAPTLSConfig{Fingerprints: []string{"zz"}},
+		},
+		Database: DatabaseConfig{
+			Path: "./test.db",
+		},
+		Web: WebConfig{
+			Port: 8080,
+		},
+		Sync: SyncConfig{
+			Interval: "soon",
+		},
+		Logging: LogConfig{
+			Level:  "loud",
+			Format: "text",
+		},
+	}
+
+	err := validate(&cfg)
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	expected := []string{
+		"imap.host is required",
+		"imap.username is required",
+		"imap.password is required",
+		"invalid log level: loud (must be debug, info, warn, or error)",
+		"invalid imap.port: 99999 (must be between 1 and 65535)",
+		`invalid sync.interval: "soon" (must be a positive duration such as 15m)`,
+		"invalid imap.tls.fingerprints entry: zz (must be a hex SHA-256 digest)",
+	}
+	if err.Error() != strings.Join(expected, "\n") {
+		t.Errorf("Expected errors:\n%s\ngot:\n%s", strings.Join(expected, "\n"), err.Error())
+	}
+}
+
+func TestLoad_DoesNotProbeFilesystem(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	dbDir := filepath.Join(tmpDir, "data")
+	if err := os.Mkdir(dbDir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	configContent := `
+imap:
+  host: imap.test.com
+  username: test@test.com
+  password: testpass
+database:
+  path: ` + filepath.Join(dbDir, "missing", "reports.db") + `
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	cfg, err := Load(configFile)
+	if err != nil {
+		t.Fatalf("Expected Load to check settings only, got: %v", err)
+	}
+	if entries, _ := os.ReadDir(dbDir); len(entries) != 0 {
+		t.Errorf("Expected Load to leave the database directory untouched, found %d entries", len(entries))
+	}
+	if err := CheckDatabasePath(cfg.Database.Path); err == nil {
+		t.Error("Expected CheckDatabasePath to report the missing directory, got nil")
+	}
+}
+
+func TestValidateWritable(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	existing := filepath.Join(tmpDir, "existing.db")
+	if err := os.WriteFile(existing, []byte("data"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		path      string
+		wantError bool
+	}{
+		{"in-memory database", ":memory:", false},
+		{"new file in writable directory", filepath.Join(tmpDir, "new.db"), false},
+		{"existing file", existing, false},
+		{"path is a directory", tmpDir, true},
+		{"missing parent directory", filepath.Join(tmpDir, "missing", "test.db"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWritable("database.path", tt.path, false)
+			if tt.wantError && err == nil {
+				t.Error("Expected error, got nil")
+			}
+			if !tt.wantError && err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		})
+	}
+
+	// The check must not leave files behind or modify existing ones
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to read directory: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected only the existing file to remain, found %d entries", len(entries))
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "data" {
+		t.Errorf("Expected existing file to be unchanged, got '%s'", data)
+	}
+}
+
+func TestCheckOutputPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	blocker := filepath.Join(tmpDir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		path      string
+		wantError bool
+	}{
+		// OpenRotatingFile and NewTracer create missing directories, so they are accepted
+		{"missing log directory", filepath.Join(tmpDir, "logs", "app", "dmarc-viewer.log"), false},
+		{"log directory under a file", filepath.Join(blocker, "logs", "dmarc-viewer.log"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, cfg := range []*Config{
+				{Logging: LogConfig{Output: "file", File: LogFileConfig{Path: tt.path}}},
+				// The tracer creates its directory the same way
+				{IMAP: IMAPConfig{Trace: IMAPTraceConfig{Enabled: true, File: tt.path}}},
+			} {
+				err := CheckOutputPaths(cfg)
+				if tt.wantError && err == nil {
+					t.Error("Expected error, got nil")
+				}
+				if !tt.wantError && err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpDir, "logs")); !os.IsNotExist(err) {
+		t.Errorf("Expected the check not to create directories, got: %v", err)
+	}
+}
+
+// Reset pflag for testing
+func resetFlags() {
+	pflag.CommandLine = pflag.NewFlagSet(os.Args[0], pflag.ExitOnError)
+}
+
+func TestLoadWithFlags_FlagErrors(t *testing.T) {
+	args, usage := os.Args, pflag.Usage
+	defer func() { os.Args, pflag.Usage = args, usage }()
+	pflag.Usage = func() {}
+
+	tests := []struct {
+		args []string
+		help bool
+	}{
+		{[]string{"--bogus"}, false},
+		{[]string{"--imap-port=abc"}, false},
+		{[]string{"--help"}, true},
+	}
+
+	for _, tt := range tests {
+		resetFlags()
+		pflag.CommandLine.SetOutput(io.Discard)
+		os.Args = append([]string{"dmarc-viewer"}, tt.args...)
+
+		_, err := LoadWithFlags()
+		if err == nil {
+			t.Errorf("%v: expected an error, got nil", tt.args)
+			continue
+		}
+		if errors.Is(err, pflag.ErrHelp) != tt.help {
+			t.Errorf("%v: expected help %v, got: %v", tt.args, tt.help, err)
+		}
+	}
+}
+
+func TestCheckCAFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	notPEM := filepath.Join(tmpDir, "not.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		caFile    string
+		wantError string
+	}{
+		{"system roots", "", ""},
+		{"missing file", filepath.Join(tmpDir, "missing.pem"), "failed to read imap.tls.ca_file"},
+		{"no certificates", notPEM, "no certificates found in imap.tls.ca_file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckCAFile(&Config{IMAP: IMAPConfig{TLS: IMAPTLSConfig{CAFile: tt.caFile}}})
+			if tt.wantError == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("Expected error containing '%s', got: %v", tt.wantError, err)
+			}
+		})
+	}
+}
+
+func TestParseFingerprint(t *testing.T) {
+	digest := strings.Repeat("ab", 32)
+
+	tests := []struct {
+		fp        string
+		wantError bool
+	}{
+		{digest, false},
+		{strings.ToUpper(digest), false},
+		{strings.TrimSuffix(strings.Repeat("AB:", 32), ":"), false},
+		{"zz", true},
+		{"abcd", true},
+	}
+
+	for _, tt := range tests {
+		pin, err := ParseFingerprint(tt.fp)
+		if tt.wantError {
+			if err == nil {
+				t.Errorf("ParseFingerprint(%s): expected error, got nil", tt.fp)
+			}
+			continue
+		}
+		if err != nil || len(pin) != 32 {
+			t.Errorf("ParseFingerprint(%s): expected a 32 byte digest, got %x, %v", tt.fp, pin, err)
+		}
+	}
+}
+
+func TestLoadWithFlags_MissingConfigFile(t *testing.T) {
+	args := os.Args
+	defer func() { os.Args = args }()
+
+	resetFlags()
+	missing := filepath.Join(t.TempDir(), "missing.yaml")
+	os.Args = []string{"dmarc-viewer", "--config", missing}
+
+	_, err := LoadWithFlags()
+	if err == nil || !strings.Contains(err.Error(), "failed to read config file") {
+		t.Errorf("Expected a read error for a missing --config, got: %v", err)
+	}
 }
@@ -0,0 +1,32 @@
+package config
+
+import "github.com/spf13/viper"
+
+// providerPreset holds the IMAP connection settings for a well-known provider
+type providerPreset struct {
+	Host   string
+	Port   int
+	UseTLS bool
+}
+
+// providerPresets maps imap.provider values to their connection settings
+var providerPresets = map[string]providerPreset{
+	"gmail":    {Host: "imap.gmail.com", Port: 993, UseTLS: true},
+	"o365":     {Host: "outlook.office365.com", Port: 993, UseTLS: true},
+	"fastmail": {Host: "imap.fastmail.com", Port: 993, UseTLS: true},
+	"yahoo":    {Host: "imap.mail.yahoo.com", Port: 993, UseTLS: true},
+}
+
+// applyProviderPreset fills in IMAP connection settings for the configured
+// provider. The preset is applied as defaults, so values set in the config
+// file, environment, or flags still take precedence. Unknown providers are
+// left for validate to report.
+func applyProviderPreset(v *viper.Viper) {
+	preset, ok := providerPresets[v.GetString("imap.provider")]
+	if !ok {
+		return
+	}
+	v.SetDefault("imap.host", preset.Host)
+	v.SetDefault("imap.port", preset.Port)
+	v.SetDefault("imap.use_tls", preset.UseTLS)
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestLoad_ProviderPreset(t *testing.T) {
+	tests := []struct {
+		name         string
+		configYAML   string
+		expectedHost string
+	}{
+		{
+			name: "preset fills host",
+			configYAML: `
+imap:
+  provider: fastmail
+  username: test@fastmail.com
+  password: testpass
+`,
+			expectedHost: "imap.fastmail.com",
+		},
+		{
+			name: "explicit host overrides preset",
+			configYAML: `
+imap:
+  provider: o365
+  host: imap.relay.example.com
+  username: test@example.com
+  password: testpass
+`,
+			expectedHost: "imap.relay.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(configFile, []byte(tt.configYAML), 0644); err != nil {
+				t.Fatalf("Failed to create config file: %v", err)
+			}
+
+			cfg, err := Load(configFile)
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+
+			if cfg.IMAP.Host != tt.expectedHost {
+				t.Errorf("Expected IMAP host '%s', got '%s'", tt.expectedHost, cfg.IMAP.Host)
+			}
+			if cfg.IMAP.Port != 993 || !cfg.IMAP.UseTLS {
+				t.Errorf("Expected port 993 with TLS, got %d (TLS %t)", cfg.IMAP.Port, cfg.IMAP.UseTLS)
+			}
+		})
+	}
+}
+
+func TestLoad_ProviderFromEnvironment(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `
+imap:
+  username: test@gmail.com
+  password: testpass
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	t.Setenv("DMARC_IMAP_PROVIDER", "gmail")
+
+	cfg, err := Load(configFile)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.IMAP.Host != "imap.gmail.com" {
+		t.Errorf("Expected IMAP host 'imap.gmail.com', got '%s'", cfg.IMAP.Host)
+	}
+	if cfg.IMAP.Provider != "gmail" {
+		t.Errorf("Expected IMAP provider 'gmail', got '%s'", cfg.IMAP.Provider)
+	}
+}
+
+func TestLoad_UnknownProviderFromEnvironment(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `
+imap:
+  username: test@gmail.com
+  password: testpass
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	t.Setenv("DMARC_IMAP_PROVIDER", "gmial")
+
+	_, err := Load(configFile)
+	if err == nil || !strings.Contains(err.Error(), "invalid imap.provider: gmial") {
+		t.Errorf("Expected invalid provider error, got: %v", err)
+	}
+}
+
+func TestLoad_UnknownProvider(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `
+imap:
+  provider: aol
+  username: test@aol.com
+  password: testpass
+`
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create config file: %v", err)
+	}
+
+	_, err := Load(configFile)
+	if err == nil {
+		t.Fatal("Expected error for unknown provider, got nil")
+	}
+	if !strings.Contains(err.Error(), "invalid imap.provider: aol") {
+		t.Errorf("Expected invalid provider error, got '%s'", err.Error())
+	}
+}
+
+func TestProviderPresets_MatchAllowedValues(t *testing.T) {
+	var names []string
+	for name := range providerPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	allowed := append([]string(nil), allowedValues["imap.provider"]...)
+	sort.Strings(allowed)
+
+	if strings.Join(names, ",") != strings.Join(allowed, ",") {
+		t.Errorf("Presets %v do not match allowed imap.provider values %v", names, allowed)
+	}
+}
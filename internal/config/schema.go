@@ -0,0 +1,103 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// schemaNode is a JSON Schema fragment describing one configuration value
+type schemaNode struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Type                 string                 `json:"type"`
+	Properties           map[string]*schemaNode `json:"properties,omitempty"`
+	AdditionalProperties interface{}            `json:"additionalProperties,omitempty"` // false, or the schema of map values
+	Items                *schemaNode            `json:"items,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Default              interface{}            `json:"default,omitempty"`
+	Minimum              *int                   `json:"minimum,omitempty"`
+	Maximum              *int                   `json:"maximum,omitempty"`
+}
+
+// numericRanges holds the inclusive bounds enforced by validate for integer settings
+var numericRanges = map[string][2]int{
+	"imap.port":           {1, 65535},
+	"web.port":            {1, 65535},
+	"privacy.ipv4_prefix": {0, 32},
+	"privacy.ipv6_prefix": {0, 128},
+}
+
+// Schema returns a JSON Schema describing the configuration file, generated
+// from the Config struct, its defaults, and the values accepted by validate
+func Schema() ([]byte, error) {
+	v := viper.New()
+	setDefaults(v)
+
+	root := schemaFor(reflect.TypeOf(Config{}), "", v)
+	root.Schema = "https://json-schema.org/draft/2020-12/schema"
+	root.Title = "DMARC Report Viewer configuration"
+
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// schemaFor builds the schema for a type found at the given dotted key
+func schemaFor(t reflect.Type, key string, v *viper.Viper) *schemaNode {
+	switch t.Kind() {
+	case reflect.Struct:
+		node := &schemaNode{
+			Type:                 "object",
+			Properties:           make(map[string]*schemaNode),
+			AdditionalProperties: false,
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			childKey := name
+			if key != "" {
+				childKey = key + "." + name
+			}
+			node.Properties[name] = schemaFor(field.Type, childKey, v)
+		}
+		return node
+	case reflect.Slice:
+		return &schemaNode{Type: "array", Items: schemaFor(t.Elem(), "", v)}
+	case reflect.Map:
+		// Map values share the allowed values of the map's key, e.g., logging.levels
+		values := &schemaNode{Type: jsonType(t.Elem().Kind()), Enum: allowedValues[key]}
+		return &schemaNode{Type: "object", AdditionalProperties: values}
+	}
+
+	node := &schemaNode{Type: jsonType(t.Kind())}
+	if key == "" {
+		return node
+	}
+	node.Enum = allowedValues[key]
+	if v.IsSet(key) {
+		node.Default = v.Get(key)
+	}
+	if r, ok := numericRanges[key]; ok {
+		node.Minimum, node.Maximum = &r[0], &r[1]
+	}
+	return node
+}
+
+// jsonType maps a Go kind to its JSON Schema type name
+func jsonType(k reflect.Kind) string {
+	switch k {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	data, err := Schema()
+	if err != nil {
+		t.Fatalf("Schema failed: %v", err)
+	}
+
+	var schema struct {
+		Schema               string `json:"$schema"`
+		Type                 string `json:"type"`
+		AdditionalProperties bool   `json:"additionalProperties"`
+		Properties           map[string]struct {
+			Type       string `json:"type"`
+			Properties map[string]struct {
+				Type    string      `json:"type"`
+				Enum    []string    `json:"enum"`
+				Default interface{} `json:"default"`
+				Minimum *int        `json:"minimum"`
+				Maximum *int        `json:"maximum"`
+			} `json:"properties"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Schema is not valid JSON: %v", err)
+	}
+
+	if schema.Schema == "" || schema.Type != "object" {
+		t.Errorf("Expected a top-level object schema, got $schema '%s' type '%s'", schema.Schema, schema.Type)
+	}
+	if schema.AdditionalProperties {
+		t.Error("Expected unknown top-level keys to be rejected")
+	}
+
+	for _, section := range []string{"imap", "database", "web", "sync", "logging", "privacy", "dns"} {
+		if schema.Properties[section].Type != "object" {
+			t.Errorf("Expected section '%s' to be an object", section)
+		}
+	}
+
+	imap := schema.Properties["imap"].Properties
+	if imap["port"].Type != "integer" {
+		t.Errorf("Expected imap.port type 'integer', got '%s'", imap["port"].Type)
+	}
+	if imap["port"].Default != float64(993) {
+		t.Errorf("Expected imap.port default 993, got %v", imap["port"].Default)
+	}
+	if imap["port"].Minimum == nil || *imap["port"].Minimum != 1 || imap["port"].Maximum == nil || *imap["port"].Maximum != 65535 {
+		t.Error("Expected imap.port range 1-65535")
+	}
+	if imap["use_tls"].Type != "boolean" {
+		t.Errorf("Expected imap.use_tls type 'boolean', got '%s'", imap["use_tls"].Type)
+	}
+	if imap["tls"].Type != "object" {
+		t.Errorf("Expected imap.tls type 'object', got '%s'", imap["tls"].Type)
+	}
+
+	level := schema.Properties["logging"].Properties["level"]
+	if len(level.Enum) != 4 || level.Default != "info" {
+		t.Errorf("Expected logging.level enum of 4 values with default 'info', got %v default %v", level.Enum, level.Default)
+	}
+}
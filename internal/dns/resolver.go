@@ -0,0 +1,180 @@
+package dns
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"dmarc-viewer/internal/config"
+	"dmarc-viewer/internal/retry"
+)
+
+// lookuper is the subset of net.Resolver used for enrichment lookups
+type lookuper interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+	LookupAddr(ctx context.Context, addr string) ([]string, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// Resolver performs DNS lookups with a per-lookup timeout and a bound on the
+// number of lookups in flight, so backfills don't hammer the resolver.
+// Temporary failures are retried according to the retry policy.
+type Resolver struct {
+	lookup  lookuper
+	timeout time.Duration
+	sem     chan struct{}
+	retry   retry.Policy
+}
+
+// NewResolver creates a Resolver from DNS configuration and a retry policy
+func NewResolver(cfg config.DNSConfig, policy retry.Policy) (*Resolver, error) {
+	timeout := 5 * time.Second
+	if cfg.Timeout != "" {
+		d, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dns.timeout: %w", err)
+		}
+		timeout = d
+	}
+
+	concurrency := cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = 10
+	}
+
+	netResolver, err := newNetResolver(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	r := newResolver(netResolver, timeout, concurrency)
+	r.retry = policy
+	return r, nil
+}
+
+// newResolver wires a Resolver around the given lookup implementation
+func newResolver(l lookuper, timeout time.Duration, concurrency int) *Resolver {
+	return &Resolver{
+		lookup:  l,
+		timeout: timeout,
+		sem:     make(chan struct{}, concurrency),
+		retry:   retry.NoRetry,
+	}
+}
+
+// newNetResolver builds the underlying net.Resolver. Without a server the
+// system resolver is used; otherwise every query is sent to the configured
+// server over the configured transport.
+func newNetResolver(cfg config.DNSConfig) (*net.Resolver, error) {
+	if cfg.Server == "" {
+		if cfg.Protocol == "tls" {
+			return nil, fmt.Errorf("dns.server is required when dns.protocol is tls")
+		}
+		return net.DefaultResolver, nil
+	}
+
+	protocol := cfg.Protocol
+	if protocol == "" {
+		protocol = "udp"
+	}
+
+	defaultPort := "53"
+	if protocol == "tls" {
+		defaultPort = "853"
+	}
+	server := cfg.Server
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, defaultPort)
+	}
+	host, _, _ := net.SplitHostPort(server)
+
+	var dial func(ctx context.Context, network, address string) (net.Conn, error)
+	switch protocol {
+	case "udp":
+		// Follow the network the resolver asks for, so a truncated UDP answer
+		// is retried over TCP
+		dial = func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, server)
+		}
+	case "tcp":
+		dial = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "tcp", server)
+		}
+	case "tls":
+		// A stream connection makes the Go resolver use TCP framing, which is
+		// what DNS over TLS expects
+		dial = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			d := tls.Dialer{Config: &tls.Config{ServerName: host}}
+			return d.DialContext(ctx, "tcp", server)
+		}
+	default:
+		return nil, fmt.Errorf("invalid dns.protocol: %s (must be udp, tcp, or tls)", protocol)
+	}
+
+	return &net.Resolver{PreferGo: true, Dial: dial}, nil
+}
+
+// LookupTXT returns the TXT records for name
+func (r *Resolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	return r.do(ctx, func(ctx context.Context) ([]string, error) {
+		return r.lookup.LookupTXT(ctx, name)
+	})
+}
+
+// LookupAddr performs a reverse lookup of an IP address
+func (r *Resolver) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	return r.do(ctx, func(ctx context.Context) ([]string, error) {
+		return r.lookup.LookupAddr(ctx, addr)
+	})
+}
+
+// LookupHost returns the addresses of host
+func (r *Resolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return r.do(ctx, func(ctx context.Context) ([]string, error) {
+		return r.lookup.LookupHost(ctx, host)
+	})
+}
+
+// do runs a lookup, retrying temporary failures. The concurrency slot is
+// released between attempts so a backoff doesn't hold up other lookups.
+func (r *Resolver) do(ctx context.Context, fn func(context.Context) ([]string, error)) ([]string, error) {
+	var result []string
+	err := retry.Do(ctx, r.retry, func(ctx context.Context) error {
+		var err error
+		result, err = r.once(ctx, fn)
+		if err != nil && !isTemporary(err) {
+			return retry.Permanent(err)
+		}
+		return err
+	})
+	return result, err
+}
+
+// isTemporary reports whether a lookup failure is worth retrying. Answers
+// such as NXDOMAIN are not.
+func isTemporary(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTemporary || dnsErr.IsTimeout
+	}
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// once runs a lookup once a concurrency slot is free, bounded by the timeout
+func (r *Resolver) once(ctx context.Context, fn func(context.Context) ([]string, error)) ([]string, error) {
+	select {
+	case r.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-r.sem }()
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	return fn(ctx)
+}
@@ -0,0 +1,216 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"dmarc-viewer/internal/config"
+	"dmarc-viewer/internal/retry"
+)
+
+// fakeLookuper records concurrency and blocks until released or cancelled
+type fakeLookuper struct {
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+	release     chan struct{}
+}
+
+func (f *fakeLookuper) wait(ctx context.Context) ([]string, error) {
+	n := f.inFlight.Add(1)
+	defer f.inFlight.Add(-1)
+	for {
+		max := f.maxInFlight.Load()
+		if n <= max || f.maxInFlight.CompareAndSwap(max, n) {
+			break
+		}
+	}
+	select {
+	case <-f.release:
+		return []string{"ok"}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (f *fakeLookuper) LookupTXT(ctx context.Context, _ string) ([]string, error) {
+	return f.wait(ctx)
+}
+
+func (f *fakeLookuper) LookupAddr(ctx context.Context, _ string) ([]string, error) {
+	return f.wait(ctx)
+}
+
+func (f *fakeLookuper) LookupHost(ctx context.Context, _ string) ([]string, error) {
+	return f.wait(ctx)
+}
+
+func TestResolver_Timeout(t *testing.T) {
+	r := newResolver(&fakeLookuper{release: make(chan struct{})}, 10*time.Millisecond, 1)
+
+	_, err := r.LookupTXT(context.Background(), "_dmarc.example.com")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestResolver_Concurrency(t *testing.T) {
+	fake := &fakeLookuper{release: make(chan struct{})}
+	r := newResolver(fake, time.Second, 2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := r.LookupAddr(context.Background(), "192.0.2.1"); err != nil {
+				t.Errorf("LookupAddr failed: %v", err)
+			}
+		}()
+	}
+
+	// Release lookups one at a time until all goroutines are done
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for {
+		select {
+		case fake.release <- struct{}{}:
+		case <-done:
+			if max := fake.maxInFlight.Load(); max > 2 {
+				t.Errorf("Expected at most 2 lookups in flight, got %d", max)
+			}
+			return
+		}
+	}
+}
+
+// flakyLookuper fails with err for the first failures calls
+type flakyLookuper struct {
+	fakeLookuper
+	err      error
+	failures int
+	calls    int
+}
+
+func (f *flakyLookuper) LookupTXT(ctx context.Context, _ string) ([]string, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, f.err
+	}
+	return []string{"v=DMARC1; p=none"}, nil
+}
+
+func TestResolver_Retry(t *testing.T) {
+	policy := retry.Policy{MaxAttempts: 3, InitialInterval: time.Millisecond}
+
+	tests := []struct {
+		name      string
+		err       error
+		wantCalls int
+		wantError bool
+	}{
+		{"temporary failure retried", &net.DNSError{Err: "server misbehaving", IsTemporary: true}, 2, false},
+		{"timeout retried", &net.DNSError{Err: "i/o timeout", IsTimeout: true}, 2, false},
+		{"not found not retried", &net.DNSError{Err: "no such host", IsNotFound: true}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &flakyLookuper{err: tt.err, failures: 1}
+			r := newResolver(fake, time.Second, 1)
+			r.retry = policy
+
+			_, err := r.LookupTXT(context.Background(), "_dmarc.example.com")
+			if tt.wantError != (err != nil) {
+				t.Errorf("Expected error %v, got: %v", tt.wantError, err)
+			}
+			if fake.calls != tt.wantCalls {
+				t.Errorf("Expected %d calls, got %d", tt.wantCalls, fake.calls)
+			}
+		})
+	}
+}
+
+func TestNewResolver(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       config.DNSConfig
+		wantError bool
+	}{
+		{"system resolver", config.DNSConfig{}, false},
+		{"custom udp server", config.DNSConfig{Server: "192.0.2.53", Protocol: "udp", Timeout: "2s"}, false},
+		{"custom tls server", config.DNSConfig{Server: "dns.example.com:853", Protocol: "tls"}, false},
+		{"tls without server", config.DNSConfig{Protocol: "tls"}, true},
+		{"invalid protocol", config.DNSConfig{Server: "192.0.2.53", Protocol: "https"}, true},
+		{"invalid timeout", config.DNSConfig{Timeout: "soon"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewResolver(tt.cfg, retry.NoRetry)
+			if tt.wantError {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if tt.cfg.Server == "" && r.lookup != net.DefaultResolver {
+				t.Error("Expected system resolver when no server is configured")
+			}
+		})
+	}
+}
+
+func TestNewResolver_Dial(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	tests := []struct {
+		protocol string
+		network  string
+		stream   bool
+	}{
+		{"udp", "udp", false},
+		{"udp", "tcp", true}, // retry after a truncated answer
+		{"tcp", "udp", true},
+		{"tcp", "tcp", true},
+	}
+
+	for _, tt := range tests {
+		r, err := newNetResolver(config.DNSConfig{Server: ln.Addr().String(), Protocol: tt.protocol})
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		conn, err := r.Dial(context.Background(), tt.network, "ignored:53")
+		if err != nil {
+			t.Fatalf("%s/%s: expected no error, got: %v", tt.protocol, tt.network, err)
+		}
+		_, packet := conn.(net.PacketConn)
+		conn.Close()
+		if packet == tt.stream {
+			t.Errorf("%s/%s: expected stream connection %v, got %T", tt.protocol, tt.network, tt.stream, conn)
+		}
+	}
+}
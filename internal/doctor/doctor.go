@@ -0,0 +1,195 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"dmarc-viewer/internal/config"
+	"dmarc-viewer/internal/dns"
+	"dmarc-viewer/internal/imap"
+	"dmarc-viewer/internal/retry"
+)
+
+// minFreeSpace is the free disk space below which the disk check fails
+const minFreeSpace = 100 << 20
+
+// checkTimeout bounds how long a single check may take
+const checkTimeout = 15 * time.Second
+
+// Kind classifies what a failing check says about the setup
+type Kind int
+
+const (
+	// Local checks cover this machine, e.g., files and disk space
+	Local Kind = iota
+	// Configuration checks cover the configuration itself
+	Configuration
+	// Remote checks depend on reaching another service
+	Remote
+)
+
+// Check is a single self-test step. Run returns an optional detail shown next
+// to a passing check, or the reason the check failed.
+type Check struct {
+	Name string
+	Kind Kind
+	Run  func(ctx context.Context) (string, error)
+}
+
+// Result is the outcome of a Check
+type Result struct {
+	Name   string
+	Kind   Kind
+	Detail string
+	Err    error
+}
+
+// Checks returns the self-test steps for the given configuration
+func Checks(cfg *config.Config) []Check {
+	return []Check{
+		{
+			Name: "Configuration is valid",
+			Kind: Configuration,
+			Run: func(ctx context.Context) (string, error) {
+				return displayFile(cfg.File), config.Validate(cfg)
+			},
+		},
+		{
+			Name: "Database path is writable",
+			Run: func(ctx context.Context) (string, error) {
+				return cfg.Database.Path, config.CheckDatabasePath(cfg.Database.Path)
+			},
+		},
+		{
+			Name: "Disk space for database",
+			Run: func(ctx context.Context) (string, error) {
+				return checkDiskSpace(filepath.Dir(cfg.Database.Path))
+			},
+		},
+		{
+			Name: "Log and trace files are writable",
+			Run: func(ctx context.Context) (string, error) {
+				var files []string
+				if cfg.Logging.Output == "file" {
+					files = append(files, cfg.Logging.File.Path)
+				}
+				if cfg.IMAP.Trace.Enabled {
+					files = append(files, cfg.IMAP.Trace.File)
+				}
+				if len(files) == 0 {
+					return "no file outputs enabled", nil
+				}
+				return strings.Join(files, ", "), config.CheckOutputPaths(cfg)
+			},
+		},
+		{
+			Name: "IMAP CA file is readable",
+			Run: func(ctx context.Context) (string, error) {
+				if cfg.IMAP.TLS.CAFile == "" {
+					return "using system roots", nil
+				}
+				return cfg.IMAP.TLS.CAFile, config.CheckCAFile(cfg)
+			},
+		},
+		requireIMAP(cfg, Check{
+			Name: "DNS resolves IMAP host",
+			Kind: Remote,
+			Run: func(ctx context.Context) (string, error) {
+				policy, err := retry.FromConfig(cfg.Retry.DNS)
+				if err != nil {
+					return "", err
+				}
+				resolver, err := dns.NewResolver(cfg.DNS, policy)
+				if err != nil {
+					return "", err
+				}
+				addrs, err := resolver.LookupHost(ctx, cfg.IMAP.Host)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%s -> %v", cfg.IMAP.Host, addrs), nil
+			},
+		}, false),
+		requireIMAP(cfg, Check{
+			Name: "IMAP connection and login",
+			Kind: Remote,
+			Run: func(ctx context.Context) (string, error) {
+				return fmt.Sprintf("%s@%s:%d", cfg.IMAP.Username, cfg.IMAP.Host, cfg.IMAP.Port), imap.Probe(ctx, &cfg.IMAP)
+			},
+		}, true),
+	}
+}
+
+// requireIMAP turns a remote check into a failed configuration check when
+// the IMAP settings it needs are missing. An empty host would otherwise dial
+// localhost and report a misleading connection failure.
+func requireIMAP(cfg *config.Config, check Check, needsUsername bool) Check {
+	var missing []string
+	if cfg.IMAP.Host == "" {
+		missing = append(missing, "imap.host")
+	}
+	if needsUsername && cfg.IMAP.Username == "" {
+		missing = append(missing, "imap.username")
+	}
+	if len(missing) == 0 {
+		return check
+	}
+
+	check.Kind = Configuration
+	check.Run = func(ctx context.Context) (string, error) {
+		return "", fmt.Errorf("skipped: %s not set", strings.Join(missing, " and "))
+	}
+	return check
+}
+
+// Run executes every check in order, each with its own timeout, and returns
+// their results. Checks don't depend on each other, so a failure doesn't stop
+// the remaining checks from running.
+func Run(ctx context.Context, checks []Check) []Result {
+	results := make([]Result, 0, len(checks))
+	for _, check := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+		detail, err := check.Run(checkCtx)
+		cancel()
+		results = append(results, Result{Name: check.Name, Kind: check.Kind, Detail: detail, Err: err})
+	}
+	return results
+}
+
+// checkDiskSpace reports the free space in dir and fails when it is low
+func checkDiskSpace(dir string) (string, error) {
+	free, err := freeSpace(dir)
+	if err != nil {
+		return "", err
+	}
+	detail := fmt.Sprintf("%s free in %s", formatBytes(free), dir)
+	if free < minFreeSpace {
+		return "", fmt.Errorf("only %s", detail)
+	}
+	return detail, nil
+}
+
+// formatBytes formats a byte count with a binary unit
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// displayFile describes which config file the settings came from
+func displayFile(file string) string {
+	if file == "" {
+		return "no config file, defaults and environment only"
+	}
+	return file
+}
@@ -0,0 +1,128 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"dmarc-viewer/internal/config"
+)
+
+func TestRun(t *testing.T) {
+	var ran []string
+	checks := []Check{
+		{Name: "first", Run: func(ctx context.Context) (string, error) {
+			ran = append(ran, "first")
+			return "", errors.New("broken")
+		}},
+		{Name: "second", Run: func(ctx context.Context) (string, error) {
+			ran = append(ran, "second")
+			if _, ok := ctx.Deadline(); !ok {
+				t.Error("Expected check context to have a deadline")
+			}
+			return "fine", nil
+		}},
+	}
+
+	results := Run(context.Background(), checks)
+
+	if len(ran) != 2 {
+		t.Fatalf("Expected both checks to run after a failure, ran %v", ran)
+	}
+	if results[0].Name != "first" || results[0].Err == nil {
+		t.Errorf("Expected first check to fail, got %+v", results[0])
+	}
+	if results[1].Name != "second" || results[1].Err != nil || results[1].Detail != "fine" {
+		t.Errorf("Expected second check to pass with detail, got %+v", results[1])
+	}
+}
+
+func TestChecks_LocalChecks(t *testing.T) {
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{
+			Path: t.TempDir() + "/test.db",
+		},
+	}
+
+	results := map[string]Result{}
+	for _, r := range Run(context.Background(), Checks(cfg)[:3]) {
+		results[r.Name] = r
+	}
+
+	if results["Configuration is valid"].Err == nil {
+		t.Error("Expected configuration check to fail for an empty config")
+	}
+	if err := results["Database path is writable"].Err; err != nil {
+		t.Errorf("Expected database check to pass, got: %v", err)
+	}
+	if err := results["Disk space for database"].Err; err != nil {
+		t.Errorf("Expected disk space check to pass, got: %v", err)
+	}
+}
+
+func TestChecks_MissingIMAPSettings(t *testing.T) {
+	cfg := &config.Config{
+		IMAP: config.IMAPConfig{Port: 993},
+	}
+
+	checks := Checks(cfg)
+	results := map[string]Result{}
+	for _, r := range Run(context.Background(), checks[len(checks)-2:]) {
+		results[r.Name] = r
+	}
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"DNS resolves IMAP host", "skipped: imap.host not set"},
+		{"IMAP connection and login", "skipped: imap.host and imap.username not set"},
+	}
+	for _, tt := range tests {
+		r := results[tt.name]
+		if r.Kind != Configuration {
+			t.Errorf("%s: expected a configuration failure, got kind %v", tt.name, r.Kind)
+		}
+		if r.Err == nil || r.Err.Error() != tt.expected {
+			t.Errorf("%s: expected error '%s', got %v", tt.name, tt.expected, r.Err)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		bytes    uint64
+		expected string
+	}{
+		{512, "512 B"},
+		{1536, "1.5 KiB"},
+		{100 << 20, "100.0 MiB"},
+		{3 << 30, "3.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		if actual := formatBytes(tt.bytes); actual != tt.expected {
+			t.Errorf("formatBytes(%d): expected '%s', got '%s'", tt.bytes, tt.expected, actual)
+		}
+	}
+}
+
+func TestChecks_Kinds(t *testing.T) {
+	cfg := &config.Config{
+		IMAP: config.IMAPConfig{Host: "imap.test.com", Username: "test@test.com"},
+	}
+	kinds := map[string]Kind{}
+	for _, c := range Checks(cfg) {
+		kinds[c.Name] = c.Kind
+	}
+
+	if kinds["Configuration is valid"] != Configuration {
+		t.Error("Expected configuration check to be of kind Configuration")
+	}
+	if kinds["Database path is writable"] != Local {
+		t.Error("Expected database check to be of kind Local")
+	}
+	if kinds["IMAP connection and login"] != Remote {
+		t.Error("Expected IMAP check to be of kind Remote")
+	}
+}
@@ -0,0 +1,184 @@
+package imap
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	"dmarc-viewer/internal/config"
+	"dmarc-viewer/internal/logging"
+)
+
+// Probe connects to the IMAP server, checks the greeting, and logs in and out
+// with the configured credentials, skipping the login when the server
+// pre-authenticates the connection. It is a lightweight connectivity check
+// that does not select a folder or fetch anything.
+func Probe(ctx context.Context, cfg *config.IMAPConfig) error {
+	conn, err := dial(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	r := bufio.NewReader(conn)
+	greeting, err := readLine(r)
+	if err != nil {
+		return fmt.Errorf("failed to read server greeting: %w", err)
+	}
+	defaultTracer.Load().server(greeting)
+	if strings.HasPrefix(greeting, "* PREAUTH") {
+		// Already authenticated; LOGIN would be answered with BAD
+		_, _ = command(conn, r, "a1", "LOGOUT")
+		return nil
+	}
+	if !strings.HasPrefix(greeting, "* OK") {
+		return fmt.Errorf("unexpected server greeting: %s", greeting)
+	}
+
+	username, err := quote(cfg.Username)
+	if err != nil {
+		return fmt.Errorf("invalid imap.username: %w", err)
+	}
+	password, err := quote(cfg.Password)
+	if err != nil {
+		return fmt.Errorf("invalid imap.password: %w", err)
+	}
+
+	status, err := command(conn, r, "a1", "LOGIN "+username+" "+password)
+	if err != nil {
+		return fmt.Errorf("login failed: %w", err)
+	}
+	if !strings.HasPrefix(status, "OK") {
+		return fmt.Errorf("login rejected: %s", status)
+	}
+
+	// The login succeeded; a failed logout doesn't change the outcome
+	_, _ = command(conn, r, "a2", "LOGOUT")
+	return nil
+}
+
+// dial opens the connection to the IMAP server, using TLS when configured
+func dial(ctx context.Context, cfg *config.IMAPConfig) (net.Conn, error) {
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+
+	if !cfg.UseTLS {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+		}
+		return conn, nil
+	}
+
+	tlsCfg, err := TLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	d := tls.Dialer{Config: tlsCfg}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	return conn, nil
+}
+
+// command sends a tagged command and returns the text of its tagged status
+// response (e.g., "OK LOGIN completed"), skipping untagged responses. The
+// exchange is logged at debug level and traced with credentials removed.
+func command(conn net.Conn, r *bufio.Reader, tag, cmd string) (string, error) {
+	log := logging.For("imap")
+	trace := defaultTracer.Load()
+	log.Debug("imap command", "line", tag+" "+sanitizeCommand(cmd))
+	trace.client(tag, cmd)
+
+	if _, err := fmt.Fprintf(conn, "%s %s\r\n", tag, cmd); err != nil {
+		return "", err
+	}
+	for {
+		line, logged, err := readResponse(r)
+		if err != nil {
+			return "", err
+		}
+		log.Debug("imap response", "line", logged)
+		trace.server(logged)
+		if rest, ok := strings.CutPrefix(line, tag+" "); ok {
+			return rest, nil
+		}
+	}
+}
+
+// maxLiteral bounds the literal size accepted from the server
+const maxLiteral = 64 << 20
+
+// readResponse reads one response, including any literals it carries, and
+// returns it along with a form that is safe to log. Literal data such as a
+// FETCH BODY[] payload is discarded and never logged; the caller only needs
+// the response text.
+func readResponse(r *bufio.Reader) (line, logged string, err error) {
+	var full, safe strings.Builder
+	for {
+		part, err := readLine(r)
+		if err != nil {
+			return "", "", err
+		}
+		full.WriteString(part)
+		safe.WriteString(sanitizeResponse(part))
+
+		size, ok := literalSize(part)
+		if !ok {
+			return full.String(), safe.String(), nil
+		}
+		if size > maxLiteral {
+			return "", "", fmt.Errorf("server literal of %d bytes exceeds limit", size)
+		}
+		if _, err := io.CopyN(io.Discard, r, size); err != nil {
+			return "", "", err
+		}
+		fmt.Fprintf(&safe, " [%d bytes omitted]", size)
+	}
+}
+
+// literalSize reports the size of the literal announced at the end of a
+// response line, e.g., "* 1 FETCH (BODY[] {2048}"
+func literalSize(line string) (int64, bool) {
+	if !strings.HasSuffix(line, "}") {
+		return 0, false
+	}
+	open := strings.LastIndexByte(line, '{')
+	if open < 0 {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(strings.TrimSuffix(line[open+1:len(line)-1], "+"), 10, 64)
+	if err != nil || size < 0 {
+		return 0, false
+	}
+	return size, true
+}
+
+// readLine reads one CRLF terminated response line
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// quote encodes s as an IMAP quoted string
+func quote(s string) (string, error) {
+	if strings.ContainsAny(s, "\r\n") {
+		return "", fmt.Errorf("must not contain line breaks")
+	}
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`, nil
+}
@@ -0,0 +1,208 @@
+package imap
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"dmarc-viewer/internal/config"
+)
+
+// newFakeServer starts a plaintext IMAP server that sends greeting and answers
+// LOGIN with loginStatus, recording the commands it receives
+func newFakeServer(t *testing.T, greeting, loginStatus string) (*config.IMAPConfig, <-chan string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	commands := make(chan string, 10)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		conn.Write([]byte(greeting + "\r\n"))
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			commands <- line
+
+			tag, cmd, _ := strings.Cut(line, " ")
+			switch {
+			case strings.HasPrefix(cmd, "LOGIN"):
+				conn.Write([]byte("* CAPABILITY IMAP4rev1\r\n" + tag + " " + loginStatus + "\r\n"))
+			case cmd == "LOGOUT":
+				conn.Write([]byte("* BYE\r\n" + tag + " OK LOGOUT completed\r\n"))
+				return
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+	return &config.IMAPConfig{
+		Host:     host,
+		Port:     portNum,
+		Username: "reports@example.com",
+		Password: `pa"ss\word`,
+	}, commands
+}
+
+func TestProbe_Success(t *testing.T) {
+	cfg, commands := newFakeServer(t, "* OK IMAP4rev1 ready", "OK LOGIN completed")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := Probe(ctx, cfg); err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+
+	login := <-commands
+	expected := `a1 LOGIN "reports@example.com" "pa\"ss\\word"`
+	if login != expected {
+		t.Errorf("Expected login command '%s', got '%s'", expected, login)
+	}
+	if logout := <-commands; logout != "a2 LOGOUT" {
+		t.Errorf("Expected logout command 'a2 LOGOUT', got '%s'", logout)
+	}
+}
+
+func TestProbe_Preauth(t *testing.T) {
+	cfg, commands := newFakeServer(t, "* PREAUTH IMAP4rev1 logged in as reports", "BAD already authenticated")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := Probe(ctx, cfg); err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+	if first := <-commands; first != "a1 LOGOUT" {
+		t.Errorf("Expected LOGOUT without LOGIN after PREAUTH, got '%s'", first)
+	}
+}
+
+func TestProbe_Failures(t *testing.T) {
+	tests := []struct {
+		name        string
+		greeting    string
+		loginStatus string
+		wantError   string
+	}{
+		{"login rejected", "* OK ready", "NO [AUTHENTICATIONFAILED] Invalid credentials", "login rejected: NO [AUTHENTICATIONFAILED] Invalid credentials"},
+		{"bad greeting", "* BYE too many connections", "OK", "unexpected server greeting: * BYE too many connections"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, _ := newFakeServer(t, tt.greeting, tt.loginStatus)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			err := Probe(ctx, cfg)
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+			if err.Error() != tt.wantError {
+				t.Errorf("Expected error '%s', got '%s'", tt.wantError, err.Error())
+			}
+			if strings.Contains(err.Error(), cfg.Password) {
+				t.Error("Error message must not contain the password")
+			}
+		})
+	}
+}
+
+func TestProbe_ConnectionRefused(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := ln.Addr().(*net.TCPAddr)
+	ln.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cfg := &config.IMAPConfig{Host: "127.0.0.1", Port: addr.Port, Username: "u", Password: "p"}
+	if err := Probe(ctx, cfg); err == nil {
+		t.Error("Expected connection error, got nil")
+	}
+}
+
+func TestQuote_RejectsLineBreaks(t *testing.T) {
+	if _, err := quote("pass\r\nword"); err == nil {
+		t.Error("Expected error for value with line breaks, got nil")
+	}
+}
+
+func TestProbe_DebugLogRedactsPassword(t *testing.T) {
+	var buf bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	cfg, _ := newFakeServer(t, "* OK IMAP4rev1 ready", "OK LOGIN completed")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := Probe(ctx, cfg); err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "word") {
+		t.Errorf("Expected password to be redacted from debug log, got %q", out)
+	}
+	if !strings.Contains(out, "a1 LOGIN") || !strings.Contains(out, "OK LOGIN completed") {
+		t.Errorf("Expected command and response in debug log, got %q", out)
+	}
+}
+
+func TestCommand_FetchBodyNotLogged(t *testing.T) {
+	var buf bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	client, server := net.Pipe()
+	defer client.Close()
+	go func() {
+		defer server.Close()
+		bufio.NewReader(server).ReadString('\n')
+		body := "Subject: DMARC report\r\n\r\nconfidential body\r\n"
+		server.Write([]byte("* 1 FETCH (UID 7 BODY[] {" + strconv.Itoa(len(body)) + "}\r\n" + body + " FLAGS (\\Seen))\r\n"))
+		server.Write([]byte("a3 OK FETCH completed\r\n"))
+	}()
+
+	status, err := command(client, bufio.NewReader(client), "a3", "UID FETCH 7 BODY[]")
+	if err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	if status != "OK FETCH completed" {
+		t.Errorf("Expected status 'OK FETCH completed', got '%s'", status)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "confidential") || strings.Contains(out, "Subject") {
+		t.Errorf("Expected message body to be kept out of the log, got %q", out)
+	}
+	if !strings.Contains(out, "bytes omitted") || !strings.Contains(out, "FLAGS") {
+		t.Errorf("Expected the FETCH response with its literal omitted, got %q", out)
+	}
+}
@@ -0,0 +1,58 @@
+package imap
+
+import (
+	"regexp"
+	"strings"
+
+	"dmarc-viewer/internal/logging"
+)
+
+// sanitizeCommand returns an untagged IMAP command with its credentials
+// replaced, so it can be logged. LOGIN keeps the username; AUTHENTICATE keeps
+// the mechanism name but not the initial response.
+func sanitizeCommand(cmd string) string {
+	name, args, _ := strings.Cut(cmd, " ")
+	switch strings.ToUpper(name) {
+	case "LOGIN":
+		user, _ := firstArg(args)
+		return name + " " + user + " " + logging.Redacted
+	case "AUTHENTICATE":
+		mechanism, rest := firstArg(args)
+		if rest == "" {
+			return cmd
+		}
+		return name + " " + mechanism + " " + logging.Redacted
+	}
+	return cmd
+}
+
+// firstArg splits the first argument, an atom or a quoted string, from the
+// rest of an argument list
+func firstArg(args string) (arg, rest string) {
+	if !strings.HasPrefix(args, `"`) {
+		arg, rest, _ = strings.Cut(args, " ")
+		return arg, rest
+	}
+
+	for i := 1; i < len(args); i++ {
+		switch args[i] {
+		case '\\':
+			i++
+		case '"':
+			return args[:i+1], strings.TrimPrefix(args[i+1:], " ")
+		}
+	}
+	// Unterminated quote; treat the whole list as the argument
+	return args, ""
+}
+
+// messageData matches message content sent as a quoted string in a FETCH
+// response, e.g., BODY[TEXT] "..." or RFC822 "..."
+var messageData = regexp.MustCompile(`(?i)((?:BODY(?:\.PEEK)?|BINARY)\[[^\]]*\](?:<\d+>)?|RFC822(?:\.TEXT|\.HEADER)?) "(?:[^"\\]|\\.)*"`)
+
+// sanitizeResponse returns a server response line with quoted message
+// content replaced. Literal content is never part of a line; readResponse
+// skips it.
+func sanitizeResponse(line string) string {
+	return messageData.ReplaceAllString(line, "$1 "+logging.Redacted)
+}
@@ -0,0 +1,50 @@
+package imap
+
+import "testing"
+
+func TestSanitizeCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmd      string
+		expected string
+	}{
+		{"login atoms", "LOGIN reports secret", "LOGIN reports [REDACTED]"},
+		{"login quoted", `LOGIN "reports@example.com" "pa\"ss word"`, `LOGIN "reports@example.com" [REDACTED]`},
+		{"login quoted user with space", `LOGIN "dmarc reports" hunter2`, `LOGIN "dmarc reports" [REDACTED]`},
+		{"lowercase login", "login reports secret", "login reports [REDACTED]"},
+		{"authenticate with initial response", "AUTHENTICATE XOAUTH2 dXNlcj1yZXBvcnRz", "AUTHENTICATE XOAUTH2 [REDACTED]"},
+		{"authenticate without initial response", "AUTHENTICATE PLAIN", "AUTHENTICATE PLAIN"},
+		{"other command", "SELECT INBOX", "SELECT INBOX"},
+		{"logout", "LOGOUT", "LOGOUT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeCommand(tt.cmd); got != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSanitizeResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected string
+	}{
+		{"quoted body", `* 1 FETCH (BODY[] "Subject: Report")`, `* 1 FETCH (BODY[] [REDACTED])`},
+		{"quoted section with escapes", `* 2 FETCH (UID 7 BODY[TEXT]<0> "say \"hi\"" FLAGS (\Seen))`, `* 2 FETCH (UID 7 BODY[TEXT]<0> [REDACTED] FLAGS (\Seen))`},
+		{"rfc822", `* 3 FETCH (RFC822.TEXT "hello")`, `* 3 FETCH (RFC822.TEXT [REDACTED])`},
+		{"no message data", "* 4 FETCH (UID 9 FLAGS (\\Seen))", "* 4 FETCH (UID 9 FLAGS (\\Seen))"},
+		{"status line", "a1 OK LOGIN completed", "a1 OK LOGIN completed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeResponse(tt.line); got != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
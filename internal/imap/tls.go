@@ -0,0 +1,62 @@
+package imap
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"dmarc-viewer/internal/config"
+)
+
+// TLSConfig builds the TLS client configuration for the IMAP connection,
+// applying the custom CA bundle, skip-verify, and fingerprint pinning options
+func TLSConfig(cfg *config.IMAPConfig) (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		ServerName:         cfg.Host,
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.TLS.InsecureSkipVerify,
+	}
+
+	if cfg.TLS.CAFile != "" {
+		pem, err := os.ReadFile(cfg.TLS.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read imap.tls.ca_file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in imap.tls.ca_file %s", cfg.TLS.CAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if len(cfg.TLS.Fingerprints) > 0 {
+		pins := make([][]byte, 0, len(cfg.TLS.Fingerprints))
+		for _, fp := range cfg.TLS.Fingerprints {
+			pin, err := config.ParseFingerprint(fp)
+			if err != nil {
+				return nil, err
+			}
+			pins = append(pins, pin)
+		}
+		// VerifyConnection runs even when InsecureSkipVerify is set, so pinning
+		// also works against self-signed certificates without a CA bundle
+		tlsCfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return fmt.Errorf("server presented no certificate")
+			}
+			sum := sha256.Sum256(cs.PeerCertificates[0].Raw)
+			for _, pin := range pins {
+				if subtle.ConstantTimeCompare(sum[:], pin) == 1 {
+					return nil
+				}
+			}
+			return fmt.Errorf("server certificate fingerprint %s does not match imap.tls.fingerprints", hex.EncodeToString(sum[:]))
+		}
+	}
+
+	return tlsCfg, nil
+}
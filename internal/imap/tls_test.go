@@ -0,0 +1,160 @@
+package imap
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"dmarc-viewer/internal/config"
+)
+
+// newTestServer starts a TLS listener with a self-signed certificate for
+// "localhost" and returns its address, the PEM certificate, and the certificate
+func newTestServer(t *testing.T) (string, []byte, *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = conn.(*tls.Conn).Handshake()
+			}()
+		}
+	}()
+
+	return ln.Addr().String(), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), cert
+}
+
+func dialTLS(addr string, cfg *tls.Config) error {
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 5 * time.Second}, "tcp", addr, cfg)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func TestTLSConfig_Verification(t *testing.T) {
+	addr, certPEM, cert := newTestServer(t)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, certPEM, 0644); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+
+	sum := sha256.Sum256(cert.Raw)
+	pin := hex.EncodeToString(sum[:])
+
+	// openssl style: upper case, colon separated
+	var pairs []string
+	for i := 0; i < len(pin); i += 2 {
+		pairs = append(pairs, strings.ToUpper(pin[i:i+2]))
+	}
+	opensslPin := strings.Join(pairs, ":")
+
+	wrongPin := strings.Repeat("00", sha256.Size)
+
+	tests := []struct {
+		name      string
+		tls       config.IMAPTLSConfig
+		wantError bool
+	}{
+		{"system roots reject private CA", config.IMAPTLSConfig{}, true},
+		{"custom CA bundle", config.IMAPTLSConfig{CAFile: caFile}, false},
+		{"insecure skip verify", config.IMAPTLSConfig{InsecureSkipVerify: true}, false},
+		{"pinned with CA bundle", config.IMAPTLSConfig{CAFile: caFile, Fingerprints: []string{pin}}, false},
+		{"pinned openssl format", config.IMAPTLSConfig{InsecureSkipVerify: true, Fingerprints: []string{opensslPin}}, false},
+		{"pin mismatch with CA bundle", config.IMAPTLSConfig{CAFile: caFile, Fingerprints: []string{wrongPin}}, true},
+		{"pin mismatch with skip verify", config.IMAPTLSConfig{InsecureSkipVerify: true, Fingerprints: []string{wrongPin}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsCfg, err := TLSConfig(&config.IMAPConfig{Host: "localhost", TLS: tt.tls})
+			if err != nil {
+				t.Fatalf("TLSConfig failed: %v", err)
+			}
+
+			err = dialTLS(addr, tlsCfg)
+			if tt.wantError && err == nil {
+				t.Error("Expected handshake error, got nil")
+			}
+			if !tt.wantError && err != nil {
+				t.Errorf("Expected handshake to succeed, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestTLSConfig_InvalidSettings(t *testing.T) {
+	emptyCA := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(emptyCA, []byte("not a certificate"), 0644); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		tls  config.IMAPTLSConfig
+	}{
+		{"missing CA file", config.IMAPTLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}},
+		{"CA file without certificates", config.IMAPTLSConfig{CAFile: emptyCA}},
+		{"fingerprint not hex", config.IMAPTLSConfig{Fingerprints: []string{"not-hex"}}},
+		{"fingerprint wrong length", config.IMAPTLSConfig{Fingerprints: []string{"abcd"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := TLSConfig(&config.IMAPConfig{Host: "localhost", TLS: tt.tls}); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package imap
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"dmarc-viewer/internal/config"
+)
+
+// Tracer writes a wire-level transcript of IMAP exchanges to its own file,
+// with credentials removed, for debugging provider-specific protocol quirks.
+// Tracing can be switched on and off while the process runs.
+type Tracer struct {
+	path    string
+	enabled atomic.Bool
+
+	mu   sync.Mutex
+	file *os.File
+	now  func() time.Time
+}
+
+// defaultTracer receives the exchanges of every connection; nil disables tracing
+var defaultTracer atomic.Pointer[Tracer]
+
+// SetTracer sets the tracer used for all IMAP connections, or disables
+// tracing when t is nil
+func SetTracer(t *Tracer) {
+	defaultTracer.Store(t)
+}
+
+// NewTracer creates a tracer writing to the configured trace file, opening
+// it straight away if tracing is enabled
+func NewTracer(cfg config.IMAPTraceConfig) (*Tracer, error) {
+	if cfg.File == "" {
+		return nil, fmt.Errorf("imap.trace.file is required")
+	}
+	t := &Tracer{path: cfg.File, now: time.Now}
+	if err := t.SetEnabled(cfg.Enabled); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// SetEnabled turns tracing on or off. The trace file is opened the first
+// time tracing is enabled and appended to from then on.
+func (t *Tracer) SetEnabled(enabled bool) error {
+	if enabled {
+		t.mu.Lock()
+		if t.file == nil {
+			if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+				t.mu.Unlock()
+				return err
+			}
+			// The trace can reveal mailbox contents, so keep it private
+			f, err := os.OpenFile(t.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+			if err != nil {
+				t.mu.Unlock()
+				return err
+			}
+			t.file = f
+		}
+		t.mu.Unlock()
+	}
+	t.enabled.Store(enabled)
+	return nil
+}
+
+// Enabled reports whether tracing is on
+func (t *Tracer) Enabled() bool {
+	return t.enabled.Load()
+}
+
+// Close closes the trace file
+func (t *Tracer) Close() error {
+	t.enabled.Store(false)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.file == nil {
+		return nil
+	}
+	err := t.file.Close()
+	t.file = nil
+	return err
+}
+
+// client records a line sent by the client, without its credentials
+func (t *Tracer) client(tag, cmd string) {
+	t.write("C", tag+" "+sanitizeCommand(cmd))
+}
+
+// server records a line received from the server
+func (t *Tracer) server(line string) {
+	t.write("S", line)
+}
+
+func (t *Tracer) write(direction, line string) {
+	if t == nil || !t.enabled.Load() {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.file == nil {
+		return
+	}
+	// Trace output is best effort and must never break the connection
+	fmt.Fprintf(t.file, "%s %s: %s\n", t.now().UTC().Format(time.RFC3339Nano), direction, line)
+}
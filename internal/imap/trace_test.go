@@ -0,0 +1,110 @@
+package imap
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"dmarc-viewer/internal/config"
+)
+
+func TestTracer_Disabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.log")
+	tracer, err := NewTracer(config.IMAPTraceConfig{File: path})
+	if err != nil {
+		t.Fatalf("NewTracer failed: %v", err)
+	}
+	defer tracer.Close()
+
+	tracer.client("a1", "NOOP")
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected no trace file while disabled, got: %v", err)
+	}
+}
+
+func TestTracer_Toggle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.log")
+	tracer, err := NewTracer(config.IMAPTraceConfig{File: path})
+	if err != nil {
+		t.Fatalf("NewTracer failed: %v", err)
+	}
+	defer tracer.Close()
+
+	if err := tracer.SetEnabled(true); err != nil {
+		t.Fatalf("SetEnabled failed: %v", err)
+	}
+	tracer.client("a1", `LOGIN "reports@example.com" "hunter2"`)
+	tracer.server("a1 OK LOGIN completed")
+
+	if err := tracer.SetEnabled(false); err != nil {
+		t.Fatalf("SetEnabled failed: %v", err)
+	}
+	tracer.client("a2", "LOGOUT")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	trace := string(data)
+	if strings.Contains(trace, "hunter2") {
+		t.Errorf("Expected password to be removed from trace, got %q", trace)
+	}
+	if !strings.Contains(trace, `C: a1 LOGIN "reports@example.com" [REDACTED]`) || !strings.Contains(trace, "S: a1 OK LOGIN completed") {
+		t.Errorf("Expected client and server lines in trace, got %q", trace)
+	}
+	if strings.Contains(trace, "LOGOUT") {
+		t.Errorf("Expected nothing traced after disabling, got %q", trace)
+	}
+
+	info, _ := os.Stat(path)
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("Expected trace file mode 0600, got %o", perm)
+	}
+}
+
+func TestProbe_Trace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.log")
+	tracer, err := NewTracer(config.IMAPTraceConfig{Enabled: true, File: path})
+	if err != nil {
+		t.Fatalf("NewTracer failed: %v", err)
+	}
+	defer tracer.Close()
+	SetTracer(tracer)
+	defer SetTracer(nil)
+
+	cfg, _ := newFakeServer(t, "* OK IMAP4rev1 ready", "OK LOGIN completed")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := Probe(ctx, cfg); err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	expected := []string{
+		"S: * OK IMAP4rev1 ready",
+		`C: a1 LOGIN "reports@example.com" [REDACTED]`,
+		"S: * CAPABILITY IMAP4rev1",
+		"S: a1 OK LOGIN completed",
+		"C: a2 LOGOUT",
+		"S: * BYE",
+		"S: a2 OK LOGOUT completed",
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d trace lines, got %q", len(expected), lines)
+	}
+	for i, want := range expected {
+		if !strings.HasSuffix(lines[i], want) {
+			t.Errorf("Expected trace line %d to end with %q, got %q", i, want, lines[i])
+		}
+	}
+}
+
+func TestNewTracer_RequiresFile(t *testing.T) {
+	if _, err := NewTracer(config.IMAPTraceConfig{Enabled: true}); err == nil {
+		t.Error("Expected error without a trace file, got nil")
+	}
+}
@@ -0,0 +1,57 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+)
+
+// ComponentKey is the attribute that names the component a logger belongs to
+const ComponentKey = "component"
+
+// For returns the default logger for a component, such as imap or web. Its
+// level follows logging.levels when the component has an override.
+func For(component string) *slog.Logger {
+	return slog.Default().With(ComponentKey, component)
+}
+
+// componentHandler filters records by level, using the level configured for
+// the component named by a ComponentKey attribute added with Logger.With
+type componentHandler struct {
+	inner   slog.Handler
+	level   slog.Level
+	levels  map[string]slog.Level
+	grouped bool // attributes added after WithGroup don't name a component
+}
+
+// newComponentHandler wraps inner, which must accept records at every level
+// in levels as well as the default level
+func newComponentHandler(inner slog.Handler, level slog.Level, levels map[string]slog.Level) *componentHandler {
+	return &componentHandler{inner: inner, level: level, levels: levels}
+}
+
+func (h *componentHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *componentHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.inner.Handle(ctx, r)
+}
+
+func (h *componentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	level := h.level
+	if !h.grouped {
+		for _, a := range attrs {
+			if a.Key != ComponentKey {
+				continue
+			}
+			if l, ok := h.levels[a.Value.String()]; ok {
+				level = l
+			}
+		}
+	}
+	return &componentHandler{inner: h.inner.WithAttrs(attrs), level: level, levels: h.levels, grouped: h.grouped}
+}
+
+func (h *componentHandler) WithGroup(name string) slog.Handler {
+	return &componentHandler{inner: h.inner.WithGroup(name), level: h.level, levels: h.levels, grouped: true}
+}
@@ -0,0 +1,56 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestComponentHandler_Levels(t *testing.T) {
+	var buf bytes.Buffer
+	inner := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	logger := slog.New(newComponentHandler(inner, slog.LevelInfo, map[string]slog.Level{
+		"imap": slog.LevelDebug,
+		"web":  slog.LevelWarn,
+	}))
+
+	tests := []struct {
+		name    string
+		logger  *slog.Logger
+		level   slog.Level
+		wantLog bool
+	}{
+		{"default info", logger, slog.LevelInfo, true},
+		{"default debug", logger, slog.LevelDebug, false},
+		{"imap debug", logger.With(ComponentKey, "imap"), slog.LevelDebug, true},
+		{"web info", logger.With(ComponentKey, "web"), slog.LevelInfo, false},
+		{"web warn", logger.With(ComponentKey, "web"), slog.LevelWarn, true},
+		{"unconfigured component", logger.With(ComponentKey, "dns"), slog.LevelDebug, false},
+		{"component inside group", logger.WithGroup("req").With(ComponentKey, "imap"), slog.LevelDebug, false},
+		{"attrs after component", logger.With(ComponentKey, "imap").With("mailbox", "INBOX"), slog.LevelDebug, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			tt.logger.Log(context.Background(), tt.level, "message")
+			if got := buf.Len() > 0; got != tt.wantLog {
+				t.Errorf("Expected logged %v, got %v (%q)", tt.wantLog, got, buf.String())
+			}
+		})
+	}
+}
+
+func TestFor(t *testing.T) {
+	var buf bytes.Buffer
+	inner := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(newComponentHandler(inner, slog.LevelInfo, map[string]slog.Level{"imap": slog.LevelDebug})))
+
+	For("imap").Debug("handshake")
+	if !strings.Contains(buf.String(), "component=imap") || !strings.Contains(buf.String(), "msg=handshake") {
+		t.Errorf("Expected imap debug record, got %q", buf.String())
+	}
+}
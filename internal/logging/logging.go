@@ -0,0 +1,93 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"dmarc-viewer/internal/config"
+)
+
+// New creates a logger from logging configuration. The returned Closer
+// flushes and closes the output and should be closed on shutdown.
+func New(cfg config.LogConfig) (*slog.Logger, io.Closer, error) {
+	opts := handlerOptions(cfg)
+
+	var (
+		h      slog.Handler
+		closer io.Closer
+	)
+	switch cfg.Output {
+	case "", "stdout":
+		h, closer = newHandler(os.Stdout, cfg.Format, opts), nopCloser{}
+	case "file":
+		f, err := OpenRotatingFile(cfg.File)
+		if err != nil {
+			return nil, nil, err
+		}
+		h, closer = newHandler(f, cfg.Format, opts), f
+	case "syslog":
+		var err error
+		if h, closer, err = newSyslogHandler(cfg.Format, opts); err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, fmt.Errorf("invalid logging.output: %s (must be stdout, file, or syslog)", cfg.Output)
+	}
+
+	return slog.New(withLevels(h, cfg)), closer, nil
+}
+
+// Stderr creates a logger with the configured format, levels and redaction
+// that writes to stderr, for when the configured output can't be opened
+func Stderr(cfg config.LogConfig) *slog.Logger {
+	return slog.New(withLevels(newHandler(os.Stderr, cfg.Format, handlerOptions(cfg)), cfg))
+}
+
+// handlerOptions returns the options for the output handler. It lets through
+// the most verbose level in use; withLevels then applies the default or
+// per-component level.
+func handlerOptions(cfg config.LogConfig) *slog.HandlerOptions {
+	minLevel := parseLevel(cfg.Level)
+	for _, name := range cfg.Levels {
+		minLevel = min(minLevel, parseLevel(name))
+	}
+	return &slog.HandlerOptions{Level: minLevel, ReplaceAttr: redactAttr}
+}
+
+// withLevels wraps an output handler with the configured default and
+// per-component levels
+func withLevels(h slog.Handler, cfg config.LogConfig) slog.Handler {
+	levels := make(map[string]slog.Level, len(cfg.Levels))
+	for component, name := range cfg.Levels {
+		levels[component] = parseLevel(name)
+	}
+	return newComponentHandler(h, parseLevel(cfg.Level), levels)
+}
+
+// newHandler creates a text or JSON handler writing to w
+func newHandler(w io.Writer, format string, opts *slog.HandlerOptions) slog.Handler {
+	if format == "json" {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
+
+// parseLevel maps a configured level name to a slog level, defaulting to info
+func parseLevel(level string) slog.Level {
+	switch level {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// nopCloser is the Closer for outputs the logger doesn't own, such as stdout
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
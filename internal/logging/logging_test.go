@@ -0,0 +1,98 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"dmarc-viewer/internal/config"
+)
+
+func TestNewHandler_Format(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(newHandler(&buf, "json", &slog.HandlerOptions{}))
+	logger.Info("sync finished", "reports", 3)
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", buf.String(), err)
+	}
+	if entry["msg"] != "sync finished" || entry["reports"] != float64(3) {
+		t.Errorf("Unexpected JSON entry: %v", entry)
+	}
+
+	buf.Reset()
+	logger = slog.New(newHandler(&buf, "text", &slog.HandlerOptions{}))
+	logger.Info("sync finished", "reports", 3)
+	if !strings.Contains(buf.String(), "msg=\"sync finished\" reports=3") {
+		t.Errorf("Expected text output, got %q", buf.String())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		level    string
+		expected slog.Level
+	}{
+		{"debug", slog.LevelDebug},
+		{"info", slog.LevelInfo},
+		{"warn", slog.LevelWarn},
+		{"error", slog.LevelError},
+		{"", slog.LevelInfo},
+	}
+
+	for _, tt := range tests {
+		if got := parseLevel(tt.level); got != tt.expected {
+			t.Errorf("parseLevel(%q): expected %s, got %s", tt.level, tt.expected, got)
+		}
+	}
+}
+
+func TestNew_FileOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "dmarc-viewer.log")
+	logger, closer, err := New(config.LogConfig{
+		Level:  "warn",
+		Format: "text",
+		Output: "file",
+		File:   config.LogFileConfig{Path: path},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	logger.Info("dropped")
+	logger.Warn("kept")
+	if err := closer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if strings.Contains(string(data), "dropped") || !strings.Contains(string(data), "kept") {
+		t.Errorf("Expected only the warning in the log file, got %q", data)
+	}
+}
+
+func TestNew_InvalidOutput(t *testing.T) {
+	if _, _, err := New(config.LogConfig{Output: "papertrail"}); err == nil {
+		t.Error("Expected error for invalid output, got nil")
+	}
+}
+
+func TestStderr(t *testing.T) {
+	logger := Stderr(config.LogConfig{Level: "warn", Levels: map[string]string{"imap": "debug"}})
+
+	if logger.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("Expected info to be filtered at the default warn level")
+	}
+	if !logger.With(ComponentKey, "imap").Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("Expected the imap override to apply to the stderr logger")
+	}
+}
@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// Redacted replaces the value of sensitive attributes
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are attribute keys whose values never reach the log output,
+// matched case-insensitively
+var sensitiveKeys = map[string]bool{
+	"password":      true,
+	"passwd":        true,
+	"secret":        true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"authorization": true,
+	"api_key":       true,
+	"hash_key":      true,
+	"cookie":        true,
+	"body":          true,
+	"message_body":  true,
+	"raw_message":   true,
+}
+
+// sensitiveSuffixes catch related keys such as imap_password or oauth_token
+var sensitiveSuffixes = []string{"_password", "_token", "_secret"}
+
+// isSensitive reports whether an attribute key names a secret or message content
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	if sensitiveKeys[key] {
+		return true
+	}
+	for _, suffix := range sensitiveSuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactAttr is a slog ReplaceAttr function that replaces the values of
+// sensitive attributes, including those inside groups
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() != slog.KindGroup && isSensitive(a.Key) {
+		return slog.String(a.Key, Redacted)
+	}
+	return a
+}
+
+// Secret is a string that is redacted whenever it is logged, whatever its key
+type Secret string
+
+// LogValue implements slog.LogValuer
+func (Secret) LogValue() slog.Value {
+	return slog.StringValue(Redacted)
+}
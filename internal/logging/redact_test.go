@@ -0,0 +1,57 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestIsSensitive(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected bool
+	}{
+		{"password", true},
+		{"Password", true},
+		{"imap_password", true},
+		{"oauth_token", true},
+		{"client_secret", true},
+		{"authorization", true},
+		{"body", true},
+		{"username", false},
+		{"host", false},
+		{"tokens_used", false},
+	}
+
+	for _, tt := range tests {
+		if got := isSensitive(tt.key); got != tt.expected {
+			t.Errorf("isSensitive(%q): expected %v, got %v", tt.key, tt.expected, got)
+		}
+	}
+}
+
+func TestRedactAttr(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(newHandler(&buf, "text", &slog.HandlerOptions{ReplaceAttr: redactAttr}))
+
+	logger.Info("login",
+		"username", "reports@example.com",
+		"password", "hunter2",
+		slog.Group("oauth", "access_token", "ya29.secret"),
+		"note", Secret("s3cr3t"),
+	)
+
+	out := buf.String()
+	for _, leaked := range []string{"hunter2", "ya29.secret", "s3cr3t"} {
+		if strings.Contains(out, leaked) {
+			t.Errorf("Expected %q to be redacted, got %q", leaked, out)
+		}
+	}
+	if !strings.Contains(out, "username=reports@example.com") {
+		t.Errorf("Expected non-sensitive attributes to be kept, got %q", out)
+	}
+	if !strings.Contains(out, "password="+Redacted) || !strings.Contains(out, "oauth.access_token="+Redacted) {
+		t.Errorf("Expected redaction markers, got %q", out)
+	}
+}
@@ -0,0 +1,319 @@
+package logging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"dmarc-viewer/internal/config"
+)
+
+// backupTimeFormat names rotated files; it sorts chronologically and avoids
+// characters that are awkward in file names
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// rotateRetryDelay is how long writes go to the current file after a failed
+// rotation before rotating is tried again
+const rotateRetryDelay = time.Minute
+
+// RotatingFile is a log file that is renamed aside and reopened once it
+// reaches a size or age limit. Rotated files are optionally gzipped and
+// pruned by count and age in the background.
+type RotatingFile struct {
+	path        string
+	maxSize     int64
+	rotateEvery time.Duration
+	maxBackups  int
+	maxAge      time.Duration
+	compress    bool
+
+	mu       sync.Mutex
+	file     *os.File
+	closed   bool
+	size     int64
+	openedAt time.Time
+	now      func() time.Time
+
+	// housekeeping serializes compression and pruning across rotations, so
+	// concurrent prunes never count the same backups
+	housekeeping sync.Mutex
+	wg           sync.WaitGroup
+
+	// onError reports rotation failures that logging recovered from. Only
+	// the first of a run of failures is reported; retryAt holds off the next
+	// attempt so a persistent failure doesn't cost a reopen on every write.
+	onError func(error)
+	failing bool
+	retryAt time.Time
+}
+
+// OpenRotatingFile opens the configured log file for appending, creating it
+// and its directory if needed
+func OpenRotatingFile(cfg config.LogFileConfig) (*RotatingFile, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("logging.file.path is required")
+	}
+
+	f := &RotatingFile{
+		path:       cfg.Path,
+		maxSize:    int64(cfg.MaxSizeMB) * 1024 * 1024,
+		maxBackups: cfg.MaxBackups,
+		compress:   cfg.Compress,
+		now:        time.Now,
+		onError: func(err error) {
+			fmt.Fprintf(os.Stderr, "log rotation failed, continuing in %s and retrying every %s: %v\n", cfg.Path, rotateRetryDelay, err)
+		},
+	}
+
+	var err error
+	if cfg.RotateEvery != "" {
+		if f.rotateEvery, err = time.ParseDuration(cfg.RotateEvery); err != nil {
+			return nil, fmt.Errorf("invalid logging.file.rotate_every: %w", err)
+		}
+	}
+	if cfg.MaxAge != "" {
+		if f.maxAge, err = time.ParseDuration(cfg.MaxAge); err != nil {
+			return nil, fmt.Errorf("invalid logging.file.max_age: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return nil, err
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write appends p to the log file, rotating first if p would take the file
+// past its size limit or the file is older than the rotation interval
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	if f.file == nil {
+		// An earlier rotation couldn't reopen the log file; try again
+		if err := f.reopen(); err != nil {
+			return 0, err
+		}
+	}
+	if f.shouldRotate(len(p)) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the log file and waits for pending compression and pruning
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	var err error
+	f.closed = true
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.mu.Unlock()
+
+	f.wg.Wait()
+	return err
+}
+
+// open opens the log file for appending and records its current size
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = f.now()
+	return nil
+}
+
+// reopen opens the log file again after a failed rotation, keeping the
+// original open time so time-based rotation stays due
+func (f *RotatingFile) reopen() error {
+	openedAt := f.openedAt
+	if err := f.open(); err != nil {
+		return err
+	}
+	f.openedAt = openedAt
+	return nil
+}
+
+// recover reports a failed rotation, unless an earlier one is still
+// unresolved, and reopens the log file for appending. It only returns an
+// error if the log file can't be reopened either.
+func (f *RotatingFile) recover(err error) error {
+	if !f.failing {
+		f.onError(err)
+	}
+	f.failing = true
+	f.retryAt = f.now().Add(rotateRetryDelay)
+	return f.reopen()
+}
+
+// shouldRotate reports whether the file must be rotated before writing n bytes.
+// An empty file is never rotated, so a single oversized write still lands.
+func (f *RotatingFile) shouldRotate(n int) bool {
+	if f.size == 0 || f.now().Before(f.retryAt) {
+		return false
+	}
+	if f.maxSize > 0 && f.size+int64(n) > f.maxSize {
+		return true
+	}
+	return f.rotateEvery > 0 && f.now().Sub(f.openedAt) >= f.rotateEvery
+}
+
+// rotate renames the current file aside, reopens the log file, and starts
+// compression and pruning of the rotated file in the background. If the
+// rename or reopen fails, e.g., on a full disk, logging carries on in the
+// current file and rotation is tried again after rotateRetryDelay.
+func (f *RotatingFile) rotate() error {
+	closeErr := f.file.Close()
+	f.file = nil
+	if closeErr != nil {
+		return f.recover(closeErr)
+	}
+
+	now := f.now()
+	backup := f.backupName(now)
+	if err := os.Rename(f.path, backup); err != nil {
+		return f.recover(err)
+	}
+	if err := f.open(); err != nil {
+		return f.recover(err)
+	}
+	f.failing = false
+
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		f.housekeeping.Lock()
+		defer f.housekeeping.Unlock()
+		if f.compress {
+			// On failure the uncompressed backup is kept
+			_ = compressFile(backup)
+		}
+		f.prune(now)
+	}()
+	return nil
+}
+
+// backupName returns the name a file rotated at t is renamed to, e.g.,
+// dmarc-viewer-2024-01-02T15-04-05.000.log. The time is in UTC so that
+// backups can be aged by their name.
+func (f *RotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(f.path)
+	base := strings.TrimSuffix(f.path, ext)
+	return base + "-" + t.UTC().Format(backupTimeFormat) + ext
+}
+
+// backup is a rotated log file and the time it was rotated
+type backup struct {
+	path    string
+	rotated time.Time
+}
+
+// backups returns the rotated files of this log, oldest first
+func (f *RotatingFile) backups() ([]backup, error) {
+	ext := filepath.Ext(f.path)
+	base := strings.TrimSuffix(f.path, ext)
+
+	matches, err := filepath.Glob(base + "-*" + ext + "*")
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []backup
+	for _, m := range matches {
+		stamp := strings.TrimSuffix(strings.TrimSuffix(m, ".gz"), ext)
+		stamp = strings.TrimPrefix(stamp, base+"-")
+		rotated, err := time.Parse(backupTimeFormat, stamp)
+		if err != nil {
+			continue
+		}
+		if info, err := os.Lstat(m); err == nil && info.Mode().IsRegular() {
+			backups = append(backups, backup{path: m, rotated: rotated})
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].rotated.Before(backups[j].rotated) })
+	return backups, nil
+}
+
+// prune deletes rotated files beyond max_backups or rotated longer than
+// max_age before now
+func (f *RotatingFile) prune(now time.Time) {
+	if f.maxBackups <= 0 && f.maxAge <= 0 {
+		return
+	}
+
+	backups, err := f.backups()
+	if err != nil {
+		return
+	}
+
+	cutoff := now.Add(-f.maxAge)
+	for i, b := range backups {
+		tooMany := f.maxBackups > 0 && len(backups)-i > f.maxBackups
+		tooOld := f.maxAge > 0 && b.rotated.Before(cutoff)
+		if tooMany || tooOld {
+			os.Remove(b.path)
+		}
+	}
+}
+
+// compressFile gzips path to path.gz and removes the original
+func compressFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := path + ".gz.tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(out)
+	_, err = io.Copy(zw, in)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := os.Rename(tmp, path+".gz"); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Remove(path)
+}
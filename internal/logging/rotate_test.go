@@ -0,0 +1,308 @@
+package logging
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"dmarc-viewer/internal/config"
+)
+
+// openTestFile opens a RotatingFile in a temp directory with a fake clock
+func openTestFile(t *testing.T, cfg config.LogFileConfig) (*RotatingFile, *time.Time) {
+	t.Helper()
+	if cfg.Path == "" {
+		cfg.Path = filepath.Join(t.TempDir(), "dmarc-viewer.log")
+	}
+
+	f, err := OpenRotatingFile(cfg)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile failed: %v", err)
+	}
+	t.Cleanup(func() { f.Close() })
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	f.now = func() time.Time { return now }
+	f.openedAt = now
+	f.onError = func(err error) { t.Logf("rotation failed: %v", err) }
+	return f, &now
+}
+
+func writeLine(t *testing.T, f *RotatingFile, line string) {
+	t.Helper()
+	if _, err := f.Write([]byte(line + "\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+}
+
+func TestRotatingFile_RotatesBySize(t *testing.T) {
+	f, now := openTestFile(t, config.LogFileConfig{})
+	f.maxSize = 10
+
+	writeLine(t, f, "first")
+	*now = now.Add(time.Second)
+	writeLine(t, f, "second")
+	f.Close()
+
+	backups, err := f.backups()
+	if err != nil {
+		t.Fatalf("backups failed: %v", err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("Expected 1 rotated file, got %v", backups)
+	}
+
+	data, _ := os.ReadFile(backups[0].path)
+	if string(data) != "first\n" {
+		t.Errorf("Expected rotated file to hold the first line, got %q", data)
+	}
+	data, _ = os.ReadFile(f.path)
+	if string(data) != "second\n" {
+		t.Errorf("Expected current file to hold the second line, got %q", data)
+	}
+}
+
+func TestRotatingFile_RotatesByAge(t *testing.T) {
+	f, now := openTestFile(t, config.LogFileConfig{RotateEvery: "24h"})
+
+	writeLine(t, f, "monday")
+	*now = now.Add(23 * time.Hour)
+	writeLine(t, f, "still monday")
+	*now = now.Add(time.Hour)
+	writeLine(t, f, "tuesday")
+	f.Close()
+
+	backups, _ := f.backups()
+	if len(backups) != 1 {
+		t.Fatalf("Expected 1 rotated file, got %v", backups)
+	}
+	data, _ := os.ReadFile(f.path)
+	if string(data) != "tuesday\n" {
+		t.Errorf("Expected current file to start after rotation, got %q", data)
+	}
+}
+
+func TestRotatingFile_Compress(t *testing.T) {
+	f, now := openTestFile(t, config.LogFileConfig{Compress: true})
+	f.maxSize = 10
+
+	writeLine(t, f, "compress me")
+	*now = now.Add(time.Second)
+	writeLine(t, f, "next")
+	f.Close()
+
+	backups, _ := f.backups()
+	if len(backups) != 1 || !strings.HasSuffix(backups[0].path, ".log.gz") {
+		t.Fatalf("Expected 1 gzipped rotated file, got %v", backups)
+	}
+
+	in, err := os.Open(backups[0].path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer in.Close()
+	zr, err := gzip.NewReader(in)
+	if err != nil {
+		t.Fatalf("gzip.NewReader failed: %v", err)
+	}
+	data, _ := io.ReadAll(zr)
+	if string(data) != "compress me\n" {
+		t.Errorf("Expected decompressed content 'compress me', got %q", data)
+	}
+}
+
+func TestRotatingFile_MaxBackups(t *testing.T) {
+	f, now := openTestFile(t, config.LogFileConfig{MaxBackups: 2})
+	f.maxSize = 10
+
+	for i := 0; i < 5; i++ {
+		writeLine(t, f, "line that rotates")
+		*now = now.Add(time.Second)
+		// Let each background prune finish so ordering is deterministic
+		f.wg.Wait()
+	}
+	f.Close()
+
+	backups, _ := f.backups()
+	if len(backups) != 2 {
+		t.Errorf("Expected 2 rotated files to be kept, got %v", backups)
+	}
+}
+
+func TestRotatingFile_MaxAge(t *testing.T) {
+	f, now := openTestFile(t, config.LogFileConfig{MaxAge: "720h"})
+
+	// Age comes from the rotation time in the name, not the file's mtime,
+	// which compression resets
+	old := f.backupName(now.Add(-1000*time.Hour)) + ".gz"
+	recent := f.backupName(now.Add(-100 * time.Hour))
+	for _, name := range []string{old, recent} {
+		if err := os.WriteFile(name, []byte("log\n"), 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+
+	f.prune(*now)
+
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("Expected rotated file older than max_age to be deleted, got: %v", err)
+	}
+	if _, err := os.Stat(recent); err != nil {
+		t.Errorf("Expected recent rotated file to be kept, got: %v", err)
+	}
+}
+
+func TestRotatingFile_ConcurrentRotations(t *testing.T) {
+	f, now := openTestFile(t, config.LogFileConfig{MaxBackups: 3, Compress: true})
+	f.maxSize = 10
+
+	// Rotate repeatedly without waiting for housekeeping in between
+	for i := 0; i < 10; i++ {
+		writeLine(t, f, "line that rotates")
+		*now = now.Add(time.Second)
+	}
+	f.Close()
+
+	backups, _ := f.backups()
+	if len(backups) != 3 {
+		t.Errorf("Expected exactly 3 rotated files to be kept, got %d", len(backups))
+	}
+}
+
+func TestRotatingFile_RenameFailureKeepsLogging(t *testing.T) {
+	f, now := openTestFile(t, config.LogFileConfig{})
+	f.maxSize = 10
+
+	var failures int
+	f.onError = func(err error) { failures++ }
+
+	// A non-empty directory at the backup name makes the rename fail
+	blocker := f.backupName(*now)
+	if err := os.MkdirAll(filepath.Join(blocker, "occupied"), 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+
+	writeLine(t, f, "first")
+	writeLine(t, f, "second")
+	if failures != 1 {
+		t.Errorf("Expected the failed rotation to be reported once, got %d", failures)
+	}
+
+	// Rotation isn't tried again until the retry delay has passed, even
+	// though the backup name is free by now
+	*now = now.Add(time.Second)
+	writeLine(t, f, "third")
+	if backups, _ := f.backups(); len(backups) != 0 {
+		t.Fatalf("Expected no rotation during the retry delay, got %v", backups)
+	}
+
+	*now = now.Add(rotateRetryDelay)
+	writeLine(t, f, "fourth")
+	f.Close()
+
+	if failures != 1 {
+		t.Errorf("Expected the retried rotation to succeed, got %d failures", failures)
+	}
+	data, _ := os.ReadFile(f.path)
+	if string(data) != "fourth\n" {
+		t.Errorf("Expected current file to hold the fourth line, got %q", data)
+	}
+	backups, _ := f.backups()
+	if len(backups) != 1 {
+		t.Fatalf("Expected 1 rotated file, got %v", backups)
+	}
+	data, _ = os.ReadFile(backups[0].path)
+	if string(data) != "first\nsecond\nthird\n" {
+		t.Errorf("Expected all lines kept through the failed rotation, got %q", data)
+	}
+}
+
+func TestRotatingFile_RepeatedFailuresReportedOnce(t *testing.T) {
+	f, now := openTestFile(t, config.LogFileConfig{})
+	f.maxSize = 10
+
+	var failures int
+	f.onError = func(err error) { failures++ }
+
+	// Block the backup names of the first two attempts
+	start := *now
+	for _, at := range []time.Time{start, start.Add(rotateRetryDelay)} {
+		if err := os.MkdirAll(filepath.Join(f.backupName(at), "occupied"), 0755); err != nil {
+			t.Fatalf("MkdirAll failed: %v", err)
+		}
+	}
+
+	writeLine(t, f, "first")
+	for i := 0; i < 5; i++ {
+		writeLine(t, f, "more")
+	}
+	*now = start.Add(rotateRetryDelay)
+	writeLine(t, f, "retried")
+	if failures != 1 {
+		t.Errorf("Expected one report for the run of failures, got %d", failures)
+	}
+
+	// Once a rotation succeeds, the next failure is reported again
+	*now = start.Add(2 * rotateRetryDelay)
+	writeLine(t, f, "rotated")
+	*now = now.Add(time.Second)
+	if err := os.MkdirAll(filepath.Join(f.backupName(*now), "occupied"), 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	writeLine(t, f, "failing again")
+	f.Close()
+
+	if failures != 2 {
+		t.Errorf("Expected a new failure after a successful rotation to be reported, got %d", failures)
+	}
+}
+
+func TestRotatingFile_WriteAfterClose(t *testing.T) {
+	f, _ := openTestFile(t, config.LogFileConfig{})
+	f.Close()
+
+	if _, err := f.Write([]byte("late\n")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected os.ErrClosed after Close, got: %v", err)
+	}
+}
+
+func TestRotatingFile_AppendsToExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	f, _ := openTestFile(t, config.LogFileConfig{Path: path})
+	writeLine(t, f, "new")
+	f.Close()
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "old\nnew\n" {
+		t.Errorf("Expected appended content, got %q", data)
+	}
+}
+
+func TestOpenRotatingFile_InvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name string
+		cfg  config.LogFileConfig
+	}{
+		{"missing path", config.LogFileConfig{}},
+		{"invalid rotate_every", config.LogFileConfig{Path: filepath.Join(dir, "a.log"), RotateEvery: "daily"}},
+		{"invalid max_age", config.LogFileConfig{Path: filepath.Join(dir, "b.log"), MaxAge: "a month"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := OpenRotatingFile(tt.cfg); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
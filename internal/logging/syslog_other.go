@@ -0,0 +1,14 @@
+//go:build windows || plan9
+
+package logging
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+)
+
+// newSyslogHandler is not implemented on this platform
+func newSyslogHandler(format string, opts *slog.HandlerOptions) (slog.Handler, io.Closer, error) {
+	return nil, nil, errors.New("logging.output syslog is not supported on this platform")
+}
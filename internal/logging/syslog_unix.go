@@ -0,0 +1,85 @@
+//go:build !windows && !plan9
+
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"log/syslog"
+	"sync"
+)
+
+// newSyslogHandler creates a handler that sends each record to the local
+// syslog daemon with a severity matching its level
+func newSyslogHandler(format string, opts *slog.HandlerOptions) (slog.Handler, io.Closer, error) {
+	w, err := syslog.New(syslog.LOG_INFO|syslog.LOG_DAEMON, "dmarc-viewer")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := &syslogOutput{w: w}
+
+	// syslog records its own timestamp
+	inner := *opts
+	inner.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && a.Key == slog.TimeKey {
+			return slog.Attr{}
+		}
+		if opts.ReplaceAttr != nil {
+			return opts.ReplaceAttr(groups, a)
+		}
+		return a
+	}
+
+	return &syslogHandler{Handler: newHandler(out, format, &inner), out: out}, w, nil
+}
+
+// syslogOutput writes formatted records to syslog at the level of the record
+// being handled. The lock is shared by every handler derived from the same
+// output so that the level and the write stay paired.
+type syslogOutput struct {
+	mu    sync.Mutex
+	w     *syslog.Writer
+	level slog.Level
+}
+
+func (o *syslogOutput) Write(p []byte) (int, error) {
+	msg := string(p)
+	var err error
+	switch {
+	case o.level >= slog.LevelError:
+		err = o.w.Err(msg)
+	case o.level >= slog.LevelWarn:
+		err = o.w.Warning(msg)
+	case o.level >= slog.LevelInfo:
+		err = o.w.Info(msg)
+	default:
+		err = o.w.Debug(msg)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// syslogHandler records the level of each record before formatting it
+type syslogHandler struct {
+	slog.Handler
+	out *syslogOutput
+}
+
+func (h *syslogHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.out.mu.Lock()
+	defer h.out.mu.Unlock()
+	h.out.level = r.Level
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *syslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &syslogHandler{Handler: h.Handler.WithAttrs(attrs), out: h.out}
+}
+
+func (h *syslogHandler) WithGroup(name string) slog.Handler {
+	return &syslogHandler{Handler: h.Handler.WithGroup(name), out: h.out}
+}
@@ -0,0 +1,109 @@
+package privacy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/netip"
+	"strings"
+
+	"dmarc-viewer/internal/config"
+)
+
+// Anonymizer applies the configured privacy policy to report data before it
+// is stored or exported
+type Anonymizer struct {
+	cfg config.PrivacyConfig
+}
+
+// New creates an Anonymizer for the given privacy settings
+func New(cfg config.PrivacyConfig) *Anonymizer {
+	// Unset recipients follow envelope_addresses, as config loading does
+	if cfg.Recipients == "" {
+		cfg.Recipients = cfg.EnvelopeAddresses
+	}
+	return &Anonymizer{cfg: cfg}
+}
+
+// SourceIP returns the source IP as it should be persisted.
+// In truncate mode the address is reduced to its network prefix; in hash mode
+// it is replaced by a keyed hash so grouping by source still works.
+// Addresses that cannot be parsed are dropped rather than stored verbatim.
+func (a *Anonymizer) SourceIP(ip string) string {
+	switch a.cfg.SourceIP {
+	case "truncate":
+		addr, err := netip.ParseAddr(ip)
+		if err != nil {
+			return ""
+		}
+		bits := a.cfg.IPv6Prefix
+		if addr.Is4() || addr.Is4In6() {
+			addr = addr.Unmap()
+			bits = a.cfg.IPv4Prefix
+		}
+		prefix, err := addr.Prefix(bits)
+		if err != nil {
+			return ""
+		}
+		return prefix.Addr().String()
+	case "hash":
+		// Hash the canonical form, so 1.2.3.4 and ::ffff:1.2.3.4, or IPv6
+		// written in different cases, group as one source
+		addr, err := netip.ParseAddr(ip)
+		if err != nil {
+			return ""
+		}
+		return a.hash(addr.Unmap().String())
+	default:
+		return ip
+	}
+}
+
+// EnvelopeAddress returns an envelope_from value as it should be persisted:
+// unchanged, reduced to its domain, or dropped entirely
+func (a *Anonymizer) EnvelopeAddress(addr string) string {
+	switch a.cfg.EnvelopeAddresses {
+	case "domain":
+		return domainOf(addr)
+	case "drop":
+		return ""
+	default:
+		return addr
+	}
+}
+
+// Recipient returns an envelope_to or forensic report recipient address as
+// it should be persisted. Hashing normalizes case first so the same mailbox
+// always maps to the same value.
+func (a *Anonymizer) Recipient(addr string) string {
+	switch a.cfg.Recipients {
+	case "hash":
+		return a.hash(strings.ToLower(strings.Trim(strings.TrimSpace(addr), "<>")))
+	case "domain":
+		return domainOf(addr)
+	case "drop":
+		return ""
+	default:
+		return addr
+	}
+}
+
+// hash returns the hex-encoded HMAC-SHA256 of value using the configured key
+func (a *Anonymizer) hash(value string) string {
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(a.cfg.HashKey))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// domainOf returns the lower-cased domain part of an address. Values without
+// an @ are already bare domains, as reporters commonly send for envelope_from.
+func domainOf(addr string) string {
+	addr = strings.TrimSpace(addr)
+	if i := strings.LastIndex(addr, "@"); i >= 0 {
+		addr = addr[i+1:]
+	}
+	return strings.ToLower(strings.Trim(addr, "<>"))
+}
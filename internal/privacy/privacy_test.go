@@ -0,0 +1,142 @@
+package privacy
+
+import (
+	"testing"
+
+	"dmarc-viewer/internal/config"
+)
+
+func TestSourceIP(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.PrivacyConfig
+		ip       string
+		expected string
+	}{
+		{"keep", config.PrivacyConfig{SourceIP: "keep"}, "192.0.2.55", "192.0.2.55"},
+		{"empty mode keeps", config.PrivacyConfig{}, "192.0.2.55", "192.0.2.55"},
+		{"truncate IPv4", config.PrivacyConfig{SourceIP: "truncate", IPv4Prefix: 24, IPv6Prefix: 64}, "192.0.2.55", "192.0.2.0"},
+		{"truncate IPv6", config.PrivacyConfig{SourceIP: "truncate", IPv4Prefix: 24, IPv6Prefix: 64}, "2001:db8:1:2:3:4:5:6", "2001:db8:1:2::"},
+		{"truncate mapped IPv4", config.PrivacyConfig{SourceIP: "truncate", IPv4Prefix: 16, IPv6Prefix: 64}, "::ffff:192.0.2.55", "192.0.0.0"},
+		{"truncate invalid", config.PrivacyConfig{SourceIP: "truncate", IPv4Prefix: 24, IPv6Prefix: 64}, "not-an-ip", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := New(tt.cfg).SourceIP(tt.ip)
+			if actual != tt.expected {
+				t.Errorf("SourceIP(%q): expected '%s', got '%s'", tt.ip, tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSourceIP_Hash(t *testing.T) {
+	a := New(config.PrivacyConfig{SourceIP: "hash", HashKey: "secret"})
+
+	first := a.SourceIP("192.0.2.55")
+	if first == "192.0.2.55" || len(first) != 64 {
+		t.Fatalf("Expected 64 character hash, got '%s'", first)
+	}
+	if again := a.SourceIP("192.0.2.55"); again != first {
+		t.Errorf("Expected stable hash, got '%s' and '%s'", first, again)
+	}
+	if other := a.SourceIP("192.0.2.56"); other == first {
+		t.Error("Expected different addresses to hash differently")
+	}
+
+	otherKey := New(config.PrivacyConfig{SourceIP: "hash", HashKey: "other"})
+	if otherKey.SourceIP("192.0.2.55") == first {
+		t.Error("Expected hash to depend on the configured key")
+	}
+}
+
+func TestSourceIP_HashCanonicalizes(t *testing.T) {
+	a := New(config.PrivacyConfig{SourceIP: "hash", HashKey: "secret"})
+
+	tests := []struct {
+		ip        string
+		canonical string
+	}{
+		{"::ffff:192.0.2.55", "192.0.2.55"},
+		{"2001:DB8::1", "2001:db8::1"},
+		{"2001:0db8:0000::0001", "2001:db8::1"},
+	}
+
+	for _, tt := range tests {
+		if actual, expected := a.SourceIP(tt.ip), a.SourceIP(tt.canonical); actual != expected {
+			t.Errorf("SourceIP(%s): expected the hash of %s '%s', got '%s'", tt.ip, tt.canonical, expected, actual)
+		}
+	}
+
+	if actual := a.SourceIP("not-an-ip"); actual != "" {
+		t.Errorf("Expected unparseable address to be dropped, got '%s'", actual)
+	}
+}
+
+func TestEnvelopeAddress(t *testing.T) {
+	tests := []struct {
+		mode     string
+		addr     string
+		expected string
+	}{
+		{"keep", "bounce@mail.example.com", "bounce@mail.example.com"},
+		{"domain", "bounce@Mail.Example.com", "mail.example.com"},
+		{"domain", "<bounce@example.com>", "example.com"},
+		{"domain", "example.com", "example.com"},
+		{"drop", "bounce@example.com", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode+"/"+tt.addr, func(t *testing.T) {
+			actual := New(config.PrivacyConfig{EnvelopeAddresses: tt.mode}).EnvelopeAddress(tt.addr)
+			if actual != tt.expected {
+				t.Errorf("EnvelopeAddress(%q): expected '%s', got '%s'", tt.addr, tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRecipient(t *testing.T) {
+	tests := []struct {
+		mode     string
+		addr     string
+		expected string
+	}{
+		{"keep", "alice@example.com", "alice@example.com"},
+		{"", "alice@example.com", "alice@example.com"},
+		{"domain", "Alice@Example.com", "example.com"},
+		{"drop", "alice@example.com", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode+"/"+tt.addr, func(t *testing.T) {
+			actual := New(config.PrivacyConfig{Recipients: tt.mode}).Recipient(tt.addr)
+			if actual != tt.expected {
+				t.Errorf("Recipient(%q): expected '%s', got '%s'", tt.addr, tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRecipient_Hash(t *testing.T) {
+	a := New(config.PrivacyConfig{Recipients: "hash", HashKey: "secret"})
+
+	hashed := a.Recipient("alice@example.com")
+	if hashed == "alice@example.com" || len(hashed) != 64 {
+		t.Fatalf("Expected 64 character hash, got '%s'", hashed)
+	}
+	if same := a.Recipient(" <Alice@Example.com> "); same != hashed {
+		t.Errorf("Expected case and bracket variants to hash the same, got '%s' and '%s'", hashed, same)
+	}
+	if a.Recipient("") != "" {
+		t.Error("Expected empty recipient to stay empty")
+	}
+}
+
+func TestRecipient_FollowsEnvelopeAddresses(t *testing.T) {
+	a := New(config.PrivacyConfig{EnvelopeAddresses: "drop"})
+	if got := a.Recipient("postmaster@example.com"); got != "" {
+		t.Errorf("Expected recipient dropped when only envelope_addresses is drop, got '%s'", got)
+	}
+}
@@ -0,0 +1,123 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"dmarc-viewer/internal/config"
+)
+
+// Policy describes how an operation is retried: up to MaxAttempts tries, with
+// the delay starting at InitialInterval and growing by Multiplier up to
+// MaxInterval. Jitter randomizes each delay by up to that fraction either way
+// so that many clients don't retry in lockstep.
+type Policy struct {
+	MaxAttempts     int
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Multiplier      float64
+	Jitter          float64
+}
+
+// NoRetry is a policy that runs an operation exactly once
+var NoRetry = Policy{MaxAttempts: 1}
+
+// FromConfig converts a configured retry policy into a Policy
+func FromConfig(cfg config.RetryPolicyConfig) (Policy, error) {
+	p := Policy{
+		MaxAttempts: cfg.MaxAttempts,
+		Multiplier:  cfg.Multiplier,
+		Jitter:      cfg.Jitter,
+	}
+
+	var err error
+	if cfg.InitialInterval != "" {
+		if p.InitialInterval, err = time.ParseDuration(cfg.InitialInterval); err != nil {
+			return Policy{}, fmt.Errorf("invalid initial_interval: %w", err)
+		}
+	}
+	if cfg.MaxInterval != "" {
+		if p.MaxInterval, err = time.ParseDuration(cfg.MaxInterval); err != nil {
+			return Policy{}, fmt.Errorf("invalid max_interval: %w", err)
+		}
+	}
+
+	return p, nil
+}
+
+// permanentError marks an error that should not be retried
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that Do returns it immediately instead of retrying
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Do runs fn until it succeeds, returns a Permanent error, the policy's
+// attempts are used up, or ctx is done. It returns the last error from fn,
+// with any Permanent wrapper removed.
+func Do(ctx context.Context, p Policy, fn func(ctx context.Context) error) error {
+	attempts := p.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn(ctx)
+		if err == nil {
+			return nil
+		}
+
+		var perm *permanentError
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+		if attempt >= attempts {
+			return err
+		}
+
+		timer := time.NewTimer(p.Delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// Delay returns the wait before the retry that follows the given attempt
+// (1 for the delay after the first try)
+func (p Policy) Delay(attempt int) time.Duration {
+	d := float64(p.InitialInterval)
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	for i := 1; i < attempt; i++ {
+		d *= multiplier
+		if p.MaxInterval > 0 && d >= float64(p.MaxInterval) {
+			break
+		}
+	}
+	if p.MaxInterval > 0 && d > float64(p.MaxInterval) {
+		d = float64(p.MaxInterval)
+	}
+
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d)
+}
@@ -0,0 +1,133 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"dmarc-viewer/internal/config"
+)
+
+var errTransient = errors.New("transient")
+
+func TestDo_RetriesUntilSuccess(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), Policy{MaxAttempts: 5, InitialInterval: time.Millisecond}, func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return errTransient
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("Expected success, got: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+func TestDo_StopsAfterMaxAttempts(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), Policy{MaxAttempts: 3, InitialInterval: time.Millisecond}, func(ctx context.Context) error {
+		calls++
+		return errTransient
+	})
+
+	if !errors.Is(err, errTransient) {
+		t.Errorf("Expected last error to be returned, got: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+func TestDo_PermanentError(t *testing.T) {
+	errRejected := errors.New("login rejected")
+	calls := 0
+	err := Do(context.Background(), Policy{MaxAttempts: 5, InitialInterval: time.Millisecond}, func(ctx context.Context) error {
+		calls++
+		return Permanent(errRejected)
+	})
+
+	if err != errRejected {
+		t.Errorf("Expected unwrapped permanent error, got: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+}
+
+func TestDo_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := Do(ctx, Policy{MaxAttempts: 5, InitialInterval: time.Hour}, func(ctx context.Context) error {
+		calls++
+		cancel()
+		return errTransient
+	})
+
+	if !errors.Is(err, errTransient) {
+		t.Errorf("Expected last error to be returned, got: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call before cancellation, got %d", calls)
+	}
+}
+
+func TestDo_NoRetry(t *testing.T) {
+	calls := 0
+	_ = Do(context.Background(), NoRetry, func(ctx context.Context) error {
+		calls++
+		return errTransient
+	})
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+}
+
+func TestPolicy_Delay(t *testing.T) {
+	p := Policy{InitialInterval: time.Second, MaxInterval: 10 * time.Second, Multiplier: 2}
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, want := range expected {
+		if got := p.Delay(i + 1); got != want {
+			t.Errorf("Delay(%d): expected %s, got %s", i+1, want, got)
+		}
+	}
+}
+
+func TestPolicy_DelayJitter(t *testing.T) {
+	p := Policy{InitialInterval: time.Second, Multiplier: 2, Jitter: 0.5}
+
+	for i := 0; i < 100; i++ {
+		d := p.Delay(2)
+		if d < time.Second || d > 3*time.Second {
+			t.Fatalf("Expected jittered delay within 1s-3s, got %s", d)
+		}
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	p, err := FromConfig(config.RetryPolicyConfig{
+		MaxAttempts:     4,
+		InitialInterval: "500ms",
+		MaxInterval:     "30s",
+		Multiplier:      1.5,
+		Jitter:          0.1,
+	})
+	if err != nil {
+		t.Fatalf("FromConfig failed: %v", err)
+	}
+
+	expected := Policy{MaxAttempts: 4, InitialInterval: 500 * time.Millisecond, MaxInterval: 30 * time.Second, Multiplier: 1.5, Jitter: 0.1}
+	if p != expected {
+		t.Errorf("Expected %+v, got %+v", expected, p)
+	}
+
+	if _, err := FromConfig(config.RetryPolicyConfig{InitialInterval: "soon"}); err == nil {
+		t.Error("Expected error for invalid interval, got nil")
+	}
+}